@@ -0,0 +1,290 @@
+package bunnystorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrJobSkipped is returned for the jobs of a batch that were not started
+// because an earlier job failed with StopOnError, or because the context was
+// done.
+const ErrJobSkipped xerrors.Error = "job skipped"
+
+// ErrorPolicy controls how a batch reacts to a failed job.
+type ErrorPolicy int
+
+// Error policies accepted by WithErrorPolicy.
+const (
+	// ContinueOnError runs every job regardless of failures. It is the
+	// default.
+	ContinueOnError ErrorPolicy = iota
+
+	// StopOnError stops starting new jobs once a job fails. Jobs already
+	// running are allowed to finish.
+	StopOnError
+)
+
+// BatchOption configures a call to Client.UploadBatch or Client.DownloadBatch.
+type BatchOption func(*batchOptions)
+
+// batchOptions holds the options of a batch.
+type batchOptions struct {
+	concurrency int
+	policy      ErrorPolicy
+}
+
+// WithBatchConcurrency sets the maximum number of jobs a batch runs at once.
+// It defaults to Config.Concurrency.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithErrorPolicy sets how a batch reacts to a failed job. It defaults to
+// ContinueOnError.
+func WithErrorPolicy(policy ErrorPolicy) BatchOption {
+	return func(o *batchOptions) {
+		o.policy = policy
+	}
+}
+
+// UploadJob describes a file uploaded by Client.UploadBatch.
+type UploadJob struct {
+	// Body is the content of the file. It is ignored if Open is set.
+	Body io.Reader
+
+	// Open, if set, is called when the job starts to obtain the content of
+	// the file, which is closed once the job is done. It allows large
+	// batches to avoid opening every file upfront.
+	Open func() (io.ReadCloser, error)
+
+	// Path is the directory the file is uploaded to.
+	Path string
+
+	// Filename is the name of the file.
+	Filename string
+
+	// Checksum is the SHA256 checksum of the file, if known.
+	Checksum string
+
+	// Options are the options of the upload.
+	Options []UploadOption
+}
+
+// DownloadJob describes a file downloaded by Client.DownloadBatch.
+type DownloadJob struct {
+	// Writer receives the content of the file. It is ignored if Create is
+	// set. If both are nil, the content is returned in BatchResult.Content.
+	Writer io.Writer
+
+	// Create, if set, is called when the job starts to obtain the
+	// destination of the file, which is closed once the job is done.
+	Create func() (io.WriteCloser, error)
+
+	// Path is the directory the file is downloaded from.
+	Path string
+
+	// Filename is the name of the file.
+	Filename string
+
+	// Options are the options of the download.
+	Options []DownloadOption
+}
+
+// BatchResult is the outcome of a single job of a batch.
+type BatchResult struct {
+	// Response is the response of the API, if the request was made.
+	Response *Response
+
+	// Err is the error of the job, if it failed. It matches ErrJobSkipped if
+	// the job was never started.
+	Err error
+
+	// Path is the directory of the job.
+	Path string
+
+	// Filename is the name of the file of the job.
+	Filename string
+
+	// Content is the downloaded content, for download jobs without a Writer
+	// or Create function.
+	Content []byte
+
+	// Index is the position of the job in the batch.
+	Index int
+}
+
+// UploadBatch uploads the files described by jobs, running up to
+// Config.Concurrency jobs at once unless WithBatchConcurrency says otherwise.
+//
+// It returns one result per job, in the same order, along with the errors of
+// the failed jobs joined together. Jobs that were not started because of
+// StopOnError or because ctx is done report an error matching ErrJobSkipped.
+func (c *Client) UploadBatch(ctx context.Context, jobs []UploadJob, opts ...BatchOption) ([]BatchResult, error) {
+	results := make([]BatchResult, len(jobs))
+
+	for i, job := range jobs {
+		results[i] = BatchResult{Index: i, Path: job.Path, Filename: job.Filename}
+	}
+
+	err := c.runBatch(ctx, results, opts, func(ctx context.Context, r *BatchResult) error {
+		job := jobs[r.Index]
+
+		body := job.Body
+		if job.Open != nil {
+			rc, err := job.Open()
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			defer rc.Close()
+
+			body = rc
+		}
+
+		resp, err := c.Upload(ctx, job.Path, job.Filename, job.Checksum, body, job.Options...)
+		r.Response = resp
+
+		return err
+	})
+
+	return results, err
+}
+
+// DownloadBatch downloads the files described by jobs. It works like
+// UploadBatch.
+func (c *Client) DownloadBatch(ctx context.Context, jobs []DownloadJob, opts ...BatchOption) ([]BatchResult, error) {
+	results := make([]BatchResult, len(jobs))
+
+	for i, job := range jobs {
+		results[i] = BatchResult{Index: i, Path: job.Path, Filename: job.Filename}
+	}
+
+	err := c.runBatch(ctx, results, opts, func(ctx context.Context, r *BatchResult) error {
+		job := jobs[r.Index]
+
+		var (
+			w      = job.Writer
+			buffer *bytes.Buffer
+			closer io.Closer
+		)
+
+		switch {
+		case job.Create != nil:
+			wc, err := job.Create()
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			w, closer = wc, wc
+		case w == nil:
+			buffer = &bytes.Buffer{}
+			w = buffer
+		}
+
+		err := c.downloadTo(ctx, w, r, job)
+
+		if closer != nil {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("%w", closeErr)
+			}
+		}
+
+		if err == nil && buffer != nil {
+			r.Content = buffer.Bytes()
+		}
+
+		return err
+	})
+
+	return results, err
+}
+
+// downloadTo streams the file of job into w.
+func (c *Client) downloadTo(ctx context.Context, w io.Writer, r *BatchResult, job DownloadJob) error {
+	body, resp, err := c.DownloadStream(ctx, job.Path, job.Filename, job.Options...)
+	r.Response = resp
+
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if _, err = io.Copy(w, body); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// runBatch runs fn for every result using a bounded pool of workers, records
+// the error of every job in its result, and returns them joined together.
+func (c *Client) runBatch(ctx context.Context, results []BatchResult, opts []BatchOption, fn func(ctx context.Context, r *BatchResult) error) error {
+	o := &batchOptions{
+		concurrency: c.cfg.Concurrency,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var (
+		started = make([]bool, len(results))
+		stopped atomic.Bool
+		errs    []error
+		mu      sync.Mutex
+	)
+
+	parallel(ctx, o.concurrency, len(results), func(ctx context.Context, i int) {
+		if stopped.Load() {
+			return
+		}
+
+		started[i] = true
+
+		r := &results[i]
+
+		if err := fn(ctx, r); err != nil {
+			r.Err = err
+
+			if o.policy == StopOnError {
+				stopped.Store(true)
+			}
+
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", joinPath(r.Path, r.Filename), err))
+			mu.Unlock()
+		}
+	})
+
+	var skipped error = ErrJobSkipped
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("%w", err))
+		skipped = fmt.Errorf("%w: %w", ErrJobSkipped, err)
+	}
+
+	for i := range results {
+		if !started[i] {
+			results[i].Err = skipped
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// joinPath returns the slash-separated path of a file in a directory, for
+// error messages.
+func joinPath(dir, filename string) string {
+	if dir == "" || dir[len(dir)-1] != '/' {
+		dir += "/"
+	}
+
+	return dir + filename
+}
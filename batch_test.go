@@ -0,0 +1,229 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+// trackingCloser counts how many times it is closed.
+type trackingCloser struct {
+	io.Reader
+	closed *atomic.Int32
+}
+
+func (c trackingCloser) Close() error {
+	c.closed.Add(1)
+
+	return nil
+}
+
+func TestClient_UploadBatch(t *testing.T) {
+	t.Parallel()
+
+	badChecksum := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name      string
+		jobs      []bunnystorage.UploadJob
+		opts      []bunnystorage.BatchOption
+		cancel    bool
+		wantFiles []string
+		wantErrs  []error
+		wantErr   error
+	}{
+		{
+			name: "all succeed",
+			jobs: []bunnystorage.UploadJob{
+				{Path: "/thumbs", Filename: "a.jpg", Body: strings.NewReader("a")},
+				{Path: "/thumbs/", Filename: "b.jpg", Body: strings.NewReader("b")},
+				{Path: "/", Filename: "c.jpg", Body: strings.NewReader("c")},
+			},
+			wantFiles: []string{"c.jpg", "thumbs/a.jpg", "thumbs/b.jpg"},
+			wantErrs:  []error{nil, nil, nil},
+		},
+		{
+			name: "continue on error",
+			jobs: []bunnystorage.UploadJob{
+				{Path: "/", Filename: "a.jpg", Body: strings.NewReader("a"), Checksum: badChecksum},
+				{Path: "/", Filename: "b.jpg", Body: strings.NewReader("b")},
+			},
+			wantFiles: []string{"b.jpg"},
+			wantErrs:  []error{bunnystorage.ErrChecksumMismatch, nil},
+			wantErr:   bunnystorage.ErrChecksumMismatch,
+		},
+		{
+			name: "stop on error",
+			jobs: []bunnystorage.UploadJob{
+				{Path: "/", Filename: "a.jpg", Body: strings.NewReader("a"), Checksum: badChecksum},
+				{Path: "/", Filename: "b.jpg", Body: strings.NewReader("b")},
+				{Path: "/", Filename: "c.jpg", Body: strings.NewReader("c")},
+			},
+			opts: []bunnystorage.BatchOption{
+				bunnystorage.WithBatchConcurrency(1),
+				bunnystorage.WithErrorPolicy(bunnystorage.StopOnError),
+			},
+			wantFiles: []string{},
+			wantErrs:  []error{bunnystorage.ErrChecksumMismatch, bunnystorage.ErrJobSkipped, bunnystorage.ErrJobSkipped},
+			wantErr:   bunnystorage.ErrChecksumMismatch,
+		},
+		{
+			name: "canceled",
+			jobs: []bunnystorage.UploadJob{
+				{Path: "/", Filename: "a.jpg", Body: strings.NewReader("a")},
+				{Path: "/", Filename: "b.jpg", Body: strings.NewReader("b")},
+			},
+			cancel:    true,
+			wantFiles: []string{},
+			wantErrs:  []error{context.Canceled, context.Canceled},
+			wantErr:   context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, srv := bunnystoragetest.NewClient(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if tt.cancel {
+				cancel()
+			}
+
+			results, err := client.UploadBatch(ctx, tt.jobs, tt.opts...)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("UploadBatch() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(results) != len(tt.jobs) {
+				t.Fatalf("UploadBatch() returned %d results, want %d", len(results), len(tt.jobs))
+			}
+
+			for i, r := range results {
+				if r.Index != i || r.Filename != tt.jobs[i].Filename {
+					t.Errorf("result %d = %+v, want job %d", i, r, i)
+				}
+
+				if !errors.Is(r.Err, tt.wantErrs[i]) || (tt.wantErrs[i] == nil) != (r.Err == nil) {
+					t.Errorf("result %d error = %v, want %v", i, r.Err, tt.wantErrs[i])
+				}
+			}
+
+			if got := srv.Files(); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestClient_UploadBatch_Open(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+
+	var opened, closed atomic.Int32
+
+	jobs := make([]bunnystorage.UploadJob, 10)
+
+	for i := range jobs {
+		content := strings.Repeat("x", i+1)
+
+		jobs[i] = bunnystorage.UploadJob{
+			Path:     "/thumbs",
+			Filename: strings.Repeat("f", i+1) + ".jpg",
+			Open: func() (io.ReadCloser, error) {
+				opened.Add(1)
+
+				return trackingCloser{Reader: strings.NewReader(content), closed: &closed}, nil
+			},
+		}
+	}
+
+	if _, err := client.UploadBatch(context.Background(), jobs, bunnystorage.WithBatchConcurrency(3)); err != nil {
+		t.Fatalf("UploadBatch() error = %v", err)
+	}
+
+	// The HTTP client may close request bodies on its own, so every body is
+	// closed at least once.
+	if opened.Load() != 10 || closed.Load() < 10 {
+		t.Errorf("opened %d and closed %d bodies, want 10", opened.Load(), closed.Load())
+	}
+
+	if got := len(srv.Files()); got != 10 {
+		t.Errorf("uploaded %d files, want 10", got)
+	}
+}
+
+// nopWriteCloser is a bytes.Buffer with a no-op Close method.
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestClient_DownloadBatch(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+
+	srv.PutFile("a.txt", []byte("alpha"))
+	srv.PutFile("dir/b.txt", []byte("bravo"))
+	srv.PutFile("dir/c.txt", []byte("charlie"))
+
+	var writer, created bytes.Buffer
+
+	jobs := []bunnystorage.DownloadJob{
+		{Path: "/", Filename: "a.txt"},
+		{Path: "/dir", Filename: "b.txt", Writer: &writer},
+		{
+			Path:     "/dir",
+			Filename: "c.txt",
+			Create: func() (io.WriteCloser, error) {
+				return nopWriteCloser{&created}, nil
+			},
+		},
+		{Path: "/", Filename: "missing.txt"},
+	}
+
+	results, err := client.DownloadBatch(context.Background(), jobs)
+	if !errors.Is(err, bunnystorage.ErrNotFound) {
+		t.Fatalf("DownloadBatch() error = %v, want %v", err, bunnystorage.ErrNotFound)
+	}
+
+	if got := string(results[0].Content); got != "alpha" {
+		t.Errorf("result 0 content = %q, want %q", got, "alpha")
+	}
+
+	if got := writer.String(); got != "bravo" {
+		t.Errorf("writer content = %q, want %q", got, "bravo")
+	}
+
+	if got := created.String(); got != "charlie" {
+		t.Errorf("created content = %q, want %q", got, "charlie")
+	}
+
+	for i, r := range results[:3] {
+		if r.Err != nil {
+			t.Errorf("result %d error = %v", i, r.Err)
+		}
+	}
+
+	if !errors.Is(results[3].Err, bunnystorage.ErrNotFound) {
+		t.Errorf("result 3 error = %v, want %v", results[3].Err, bunnystorage.ErrNotFound)
+	}
+}
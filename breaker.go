@@ -0,0 +1,319 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrCircuitOpen is returned when a request is not sent because the circuit
+// breaker of its endpoint and operation is open. It is never retried.
+const ErrCircuitOpen xerrors.Error = "circuit breaker is open"
+
+// Default values for the CircuitBreaker struct.
+const (
+	DefaultFailureThreshold int           = 5
+	DefaultBreakerCooldown  time.Duration = 30 * time.Second
+	DefaultHalfOpenRequests int           = 1
+)
+
+// The states of a circuit breaker.
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails every request with ErrCircuitOpen.
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of trial requests through to find
+	// out whether the endpoint recovered.
+	CircuitHalfOpen
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// String returns the string representation of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker configures the circuit breakers of a Client. Every endpoint
+// has separate breakers for read and write operations, which open after
+// FailureThreshold consecutive requests fail with a network error or a 5xx
+// status code. An open breaker fails requests with ErrCircuitOpen until
+// Cooldown elapses, then lets HalfOpenRequests trial requests through: the
+// breaker closes if they succeed and opens again if one fails.
+type CircuitBreaker struct {
+	// OnStateChange is called whenever a breaker changes state.
+	//
+	// This field is optional.
+	OnStateChange func(event CircuitEvent)
+
+	// FailureThreshold is the number of consecutive failures opening a
+	// breaker. It defaults to DefaultFailureThreshold.
+	//
+	// This field is optional.
+	FailureThreshold int
+
+	// Cooldown is the time an open breaker waits before letting trial
+	// requests through. It defaults to DefaultBreakerCooldown.
+	//
+	// This field is optional.
+	Cooldown time.Duration
+
+	// HalfOpenRequests is the number of concurrent trial requests allowed by
+	// a half-open breaker. It defaults to DefaultHalfOpenRequests.
+	//
+	// This field is optional.
+	HalfOpenRequests int
+}
+
+// CircuitEvent describes a circuit breaker changing state.
+type CircuitEvent struct {
+	// BaseURL is the base URL of the endpoint guarded by the breaker.
+	BaseURL string
+
+	// Operation is the operation guarded by the breaker.
+	Operation Operation
+
+	// From is the previous state of the breaker.
+	From CircuitState
+
+	// To is the new state of the breaker.
+	To CircuitState
+}
+
+// withDefaults returns a copy of b with its unset fields set to their default
+// values.
+func (b *CircuitBreaker) withDefaults() *CircuitBreaker {
+	policy := *b
+
+	if policy.FailureThreshold < 1 {
+		policy.FailureThreshold = DefaultFailureThreshold
+	}
+
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultBreakerCooldown
+	}
+
+	if policy.HalfOpenRequests < 1 {
+		policy.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+
+	return &policy
+}
+
+// breaker is the circuit breaker of a single endpoint and operation.
+type breaker struct {
+	policy   *CircuitBreaker
+	openedAt time.Time
+	state    CircuitState
+	failures int
+	trials   int
+	mu       sync.Mutex
+}
+
+// allow reports whether a request may be sent, moving an open breaker to the
+// half-open state once its cooldown elapsed, and returns the states of the
+// breaker before and after the call.
+func (b *breaker) allow(now time.Time) (allowed bool, from, to CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+
+	if b.state == CircuitOpen {
+		if now.Sub(b.openedAt) < b.policy.Cooldown {
+			return false, from, b.state
+		}
+
+		b.state = CircuitHalfOpen
+		b.trials = 0
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.trials >= b.policy.HalfOpenRequests {
+			return false, from, b.state
+		}
+
+		b.trials++
+	}
+
+	return true, from, b.state
+}
+
+// record records the outcome of a request let through by allow, and returns
+// the states of the breaker before and after it.
+func (b *breaker) record(now time.Time, failed bool) (from, to CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+
+	switch {
+	case !failed:
+		b.state = CircuitClosed
+		b.failures = 0
+	case b.state == CircuitHalfOpen:
+		b.state = CircuitOpen
+		b.openedAt = now
+	default:
+		b.failures++
+
+		if b.failures >= b.policy.FailureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = now
+		}
+	}
+
+	return from, b.state
+}
+
+// release gives back the trial slot taken by a request whose outcome is not
+// recorded.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.trials > 0 {
+		b.trials--
+	}
+}
+
+// breakerKey identifies the breaker of an endpoint and operation.
+type breakerKey struct {
+	baseURL   string
+	operation Operation
+}
+
+// breakerTransport is an http.RoundTripper guarding every endpoint and
+// operation with a circuit breaker.
+type breakerTransport struct {
+	next     http.RoundTripper
+	policy   *CircuitBreaker
+	breakers map[breakerKey]*breaker
+	now      func() time.Time
+	baseURLs []string
+	mu       sync.Mutex
+}
+
+// newBreakerTransport wraps next with the circuit breakers of the given
+// Config, or returns next unchanged if it has none.
+func newBreakerTransport(next http.RoundTripper, cfg *Config) http.RoundTripper {
+	if cfg.CircuitBreaker == nil {
+		return next
+	}
+
+	return &breakerTransport{
+		next:     next,
+		policy:   cfg.CircuitBreaker.withDefaults(),
+		breakers: make(map[breakerKey]*breaker),
+		now:      cfg.now,
+		baseURLs: append([]string{cfg.baseURL()}, cfg.fallbackURLs()...),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := breakerKey{
+		baseURL:   t.baseURL(req),
+		operation: operationFor(req.Method),
+	}
+
+	b := t.breaker(key)
+
+	allowed, from, to := b.allow(t.now())
+	t.notify(key, from, to)
+
+	if !allowed {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		return nil, fmt.Errorf("%w: %s %s", ErrCircuitOpen, req.Method, key.baseURL)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	ctx := req.Context()
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		// The caller gave up on the request, which says nothing about the
+		// health of the endpoint, so release the trial slot without
+		// recording an outcome.
+		b.release()
+
+		return resp, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+	}
+
+	from, to = b.record(t.now(), isBreakerFailure(resp, err))
+	t.notify(key, from, to)
+
+	return resp, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+}
+
+// breaker returns the breaker for the given key, creating it if needed.
+func (t *breakerTransport) breaker(key breakerKey) *breaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.breakers[key]
+	if !ok {
+		b = &breaker{policy: t.policy}
+		t.breakers[key] = b
+	}
+
+	return b
+}
+
+// baseURL returns the base URL of the endpoint req is sent to.
+func (t *breakerTransport) baseURL(req *http.Request) string {
+	uri := req.URL.String()
+
+	for _, baseURL := range t.baseURLs {
+		if strings.HasPrefix(uri, baseURL+"/") {
+			return baseURL
+		}
+	}
+
+	return req.URL.Scheme + "://" + req.URL.Host
+}
+
+// notify calls the OnStateChange hook if the state of a breaker changed.
+func (t *breakerTransport) notify(key breakerKey, from, to CircuitState) {
+	if from == to || t.policy.OnStateChange == nil {
+		return
+	}
+
+	t.policy.OnStateChange(CircuitEvent{
+		BaseURL:   key.baseURL,
+		Operation: key.operation,
+		From:      from,
+		To:        to,
+	})
+}
+
+// isBreakerFailure reports whether a request that produced the given response
+// and error counts as a failure of the endpoint.
+func isBreakerFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, ErrRateLimitWait)
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,206 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// circuitRecorder records the state changes of circuit breakers.
+type circuitRecorder struct {
+	events []bunnystorage.CircuitEvent
+	mu     sync.Mutex
+}
+
+func (r *circuitRecorder) record(event bunnystorage.CircuitEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *circuitRecorder) transitions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transitions := make([]string, 0, len(r.events))
+
+	for _, event := range r.events {
+		transitions = append(transitions, event.From.String()+" -> "+event.To.String())
+	}
+
+	return transitions
+}
+
+// fakeClock is a clock that only moves when advanced.
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2023, time.April, 20, 15, 32, 8, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestConfig_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	const primary = "storage.invalid"
+
+	rt := newRegionTransport(map[string]int{primary: http.StatusInternalServerError})
+	events := &circuitRecorder{}
+	clock := newFakeClock()
+
+	cfg := &bunnystorage.Config{
+		Transport:  rt,
+		MaxRetries: 5,
+		RetryPolicy: &bunnystorage.RetryPolicy{
+			MinDelay: time.Millisecond,
+			MaxDelay: time.Millisecond,
+		},
+		CircuitBreaker: &bunnystorage.CircuitBreaker{
+			FailureThreshold: 3,
+			Cooldown:         time.Minute,
+			OnStateChange:    events.record,
+		},
+	}
+
+	bunnystorage.SetClock(cfg, clock.Now)
+
+	client := newTransportClient(t, cfg)
+
+	ctx := context.Background()
+
+	// The breaker opens after three failed attempts and stops the retries.
+	if _, _, err := client.List(ctx, "/"); !errors.Is(err, bunnystorage.ErrCircuitOpen) {
+		t.Fatalf("List() error = %v, want %v", err, bunnystorage.ErrCircuitOpen)
+	}
+
+	if got := rt.count(primary); got != 3 {
+		t.Errorf("transport received %d requests, want 3", got)
+	}
+
+	// Writes have a breaker of their own.
+	rt.setStatus(primary, http.StatusCreated)
+
+	if _, err := client.Upload(ctx, "/", "file.txt", "", strings.NewReader("content")); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	// A failed trial request opens the breaker again.
+	rt.setStatus(primary, http.StatusBadGateway)
+	clock.Advance(time.Minute)
+
+	if _, _, err := client.List(ctx, "/"); !errors.Is(err, bunnystorage.ErrCircuitOpen) {
+		t.Fatalf("List() error = %v, want %v", err, bunnystorage.ErrCircuitOpen)
+	}
+
+	if got := rt.count(primary); got != 5 {
+		t.Errorf("transport received %d requests, want 5", got)
+	}
+
+	// A successful trial request closes the breaker.
+	rt.setStatus(primary, http.StatusOK)
+	clock.Advance(time.Minute)
+
+	if _, _, err := client.List(ctx, "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := []string{
+		"closed -> open",
+		"open -> half-open",
+		"half-open -> open",
+		"open -> half-open",
+		"half-open -> closed",
+	}
+
+	if got := events.transitions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+
+	for _, event := range events.events {
+		if event.BaseURL != "http://"+primary || event.Operation != bunnystorage.OperationRead {
+			t.Errorf("event = %+v, want the read breaker of %s", event, primary)
+		}
+	}
+}
+
+func TestConfig_CircuitBreaker_Fallbacks(t *testing.T) {
+	t.Parallel()
+
+	const (
+		primary   = "storage.invalid"
+		secondary = "secondary.invalid"
+	)
+
+	rt := newRegionTransport(map[string]int{
+		primary:   http.StatusServiceUnavailable,
+		secondary: http.StatusOK,
+	})
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport:        rt,
+		FallbackBaseURLs: []string{"http://" + secondary},
+		FailoverCooldown: time.Nanosecond,
+		CircuitBreaker: &bunnystorage.CircuitBreaker{
+			FailureThreshold: 2,
+			Cooldown:         time.Hour,
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		if _, _, err := client.List(context.Background(), "/"); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+	}
+
+	if got := rt.count(primary); got != 2 {
+		t.Errorf("primary received %d requests, want 2", got)
+	}
+
+	if got := rt.count(secondary); got != 5 {
+		t.Errorf("secondary received %d requests, want 5", got)
+	}
+}
+
+func TestCircuitState_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state bunnystorage.CircuitState
+		want  string
+	}{
+		{state: bunnystorage.CircuitClosed, want: "closed"},
+		{state: bunnystorage.CircuitOpen, want: "open"},
+		{state: bunnystorage.CircuitHalfOpen, want: "half-open"},
+		{state: bunnystorage.CircuitState(42), want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("CircuitState(%d).String() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
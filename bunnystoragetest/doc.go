@@ -0,0 +1,10 @@
+// Package bunnystoragetest provides an in-memory fake of the [Edge Storage
+// API] for use in tests.
+//
+// The fake implements uploads with checksum validation, downloads, deletion of
+// files and directories, and directory listings, and serves them over real
+// HTTP on a random local port, so code built on [bunnystorage.Client] can be
+// tested without network access.
+//
+// [Edge Storage API]: https://docs.bunny.net/reference/storage-api
+package bunnystoragetest
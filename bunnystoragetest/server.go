@@ -0,0 +1,463 @@
+package bunnystoragetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// Default credentials accepted by the fake server.
+const (
+	DefaultStorageZone string = "test-zone"
+	DefaultKey         string = "test-key"
+	DefaultReadOnlyKey string = "test-read-only-key"
+)
+
+// timeFormat is the layout used by the Edge Storage API for timestamps.
+const timeFormat string = "2006-01-02T15:04:05.000"
+
+// userID is the user ID reported for every object.
+const userID string = "00000000-0000-0000-0000-000000000000"
+
+// Server is an in-memory fake of the Edge Storage API serving a single storage
+// zone.
+type Server struct {
+	// URL is the base URL of the server, in the form http://ipaddr:port with
+	// no trailing slash.
+	URL string
+
+	// StorageZone is the name of the storage zone served by the server.
+	StorageZone string
+
+	// Key is the access key required for write operations. It is also
+	// accepted for read operations.
+	Key string
+
+	// ReadOnlyKey is the access key accepted for read operations.
+	ReadOnlyKey string
+
+	// srv is the underlying HTTP test server.
+	srv *httptest.Server
+
+	// files holds the stored files, keyed by their path relative to the root
+	// of the storage zone.
+	files map[string]*file
+
+	// dirs holds the creation time of every directory, keyed by its path
+	// relative to the root of the storage zone.
+	dirs map[string]time.Time
+
+	// guid is the counter used to assign object GUIDs.
+	guid int
+
+	// mu protects files, dirs and guid.
+	mu sync.RWMutex
+}
+
+// file is a file stored in the fake storage zone.
+type file struct {
+	content  []byte
+	checksum string
+	guid     string
+	created  time.Time
+	changed  time.Time
+}
+
+// NewServer starts and returns a new Server using the default storage zone and
+// credentials. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		StorageZone: DefaultStorageZone,
+		Key:         DefaultKey,
+		ReadOnlyKey: DefaultReadOnlyKey,
+		files:       make(map[string]*file),
+		dirs:        make(map[string]time.Time),
+	}
+
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// NewClient starts a new Server and returns a Client connected to it. The
+// server is shut down when the test and all its subtests complete.
+func NewClient(tb testing.TB) (*bunnystorage.Client, *Server) {
+	tb.Helper()
+
+	s := NewServer()
+	tb.Cleanup(s.Close)
+
+	client, err := bunnystorage.NewClient(s.Config())
+	if err != nil {
+		tb.Fatalf("failed to initialize test client: %v", err)
+	}
+
+	return client, s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on it
+// have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Config returns a new Config for a Client connected to the server.
+func (s *Server) Config() *bunnystorage.Config {
+	return &bunnystorage.Config{
+		StorageZone: s.StorageZone,
+		Key:         s.Key,
+		ReadOnlyKey: s.ReadOnlyKey,
+		BaseURL:     s.URL,
+	}
+}
+
+// PutFile stores a file in the storage zone, creating its parent directories
+// as needed. The name is relative to the root of the storage zone.
+func (s *Server) PutFile(name string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(strings.Trim(name, "/"), content)
+}
+
+// File returns the content of the named file and whether it exists.
+func (s *Server) File(name string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.files[strings.Trim(name, "/")]
+	if !ok {
+		return nil, false
+	}
+
+	return bytes.Clone(f.content), true
+}
+
+// Files returns the sorted names of every file in the storage zone.
+func (s *Server) Files() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.files))
+
+	for name := range s.files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	zone, name, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !ok || zone != s.StorageZone {
+		writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+
+		return
+	}
+
+	var (
+		key   = r.Header.Get("AccessKey")
+		isDir = name == "" || strings.HasSuffix(name, "/")
+	)
+
+	name = path.Clean("/" + name)[1:]
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if key != s.Key && key != s.ReadOnlyKey {
+			writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+
+			return
+		}
+
+		if isDir {
+			s.list(w, name)
+
+			return
+		}
+
+		s.download(w, r, name)
+	case http.MethodPut:
+		if key != s.Key {
+			writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+
+			return
+		}
+
+		if isDir {
+			writeStatus(w, http.StatusBadRequest, "Invalid path")
+
+			return
+		}
+
+		s.upload(w, r, name)
+	case http.MethodDelete:
+		if key != s.Key {
+			writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+
+			return
+		}
+
+		s.delete(w, name, isDir)
+	default:
+		writeStatus(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// list writes the listing of the named directory.
+func (s *Server) list(w http.ResponseWriter, dir string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.dirs[dir]; !ok && dir != "" {
+		writeStatus(w, http.StatusNotFound, "Object Not Found")
+
+		return
+	}
+
+	objects := make([]*bunnystorage.Object, 0)
+
+	for name, created := range s.dirs {
+		if parent(name) == dir {
+			objects = append(objects, s.directoryObject(name, created))
+		}
+	}
+
+	for name, f := range s.files {
+		if parent(name) == dir {
+			objects = append(objects, s.fileObject(name, f))
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].ObjectName < objects[j].ObjectName
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(objects); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// download writes the content of the named file, honoring range and
+// conditional requests.
+func (s *Server) download(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.RLock()
+
+	f, ok := s.files[name]
+	if ok {
+		// Copy the file, since put replaces its fields once the lock is
+		// released.
+		f = &file{
+			content:  f.content,
+			checksum: f.checksum,
+			changed:  f.changed,
+		}
+	}
+
+	s.mu.RUnlock()
+
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "Object Not Found")
+
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	http.ServeContent(w, r, path.Base(name), f.changed, bytes.NewReader(f.content))
+}
+
+// upload stores the request body as the named file, validating the Checksum
+// header if present.
+func (s *Server) upload(w http.ResponseWriter, r *http.Request, name string) {
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, "Unable to read request body")
+
+		return
+	}
+
+	if want := r.Header.Get("Checksum"); want != "" && !strings.EqualFold(want, checksum(content)) {
+		writeStatus(w, http.StatusBadRequest, "Checksum and file hash do not match")
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.dirs[name]; ok {
+		writeStatus(w, http.StatusBadRequest, "Invalid path")
+
+		return
+	}
+
+	s.put(name, content)
+
+	writeStatus(w, http.StatusCreated, "File uploaded.")
+}
+
+// delete removes the named file, or the named directory and everything in it.
+func (s *Server) delete(w http.ResponseWriter, name string, isDir bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !isDir {
+		if _, ok := s.files[name]; !ok {
+			writeStatus(w, http.StatusNotFound, "Object Not Found")
+
+			return
+		}
+
+		delete(s.files, name)
+
+		writeStatus(w, http.StatusOK, "File deleted successfuly.") //nolint:misspell // matches the API.
+
+		return
+	}
+
+	if _, ok := s.dirs[name]; !ok && name != "" {
+		writeStatus(w, http.StatusNotFound, "Object Not Found")
+
+		return
+	}
+
+	for key := range s.files {
+		if isWithin(key, name) {
+			delete(s.files, key)
+		}
+	}
+
+	for key := range s.dirs {
+		if key == name || isWithin(key, name) {
+			delete(s.dirs, key)
+		}
+	}
+
+	writeStatus(w, http.StatusOK, "Directory deleted successfuly.") //nolint:misspell // matches the API.
+}
+
+// put stores a file and creates its parent directories. The caller must hold
+// the write lock.
+func (s *Server) put(name string, content []byte) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	for dir := parent(name); dir != ""; dir = parent(dir) {
+		if _, ok := s.dirs[dir]; !ok {
+			s.dirs[dir] = now
+		}
+	}
+
+	f, ok := s.files[name]
+	if !ok {
+		s.guid++
+
+		f = &file{
+			guid:    fmt.Sprintf("00000000-0000-0000-0000-%012d", s.guid),
+			created: now,
+		}
+
+		s.files[name] = f
+	}
+
+	f.content = bytes.Clone(content)
+	f.checksum = checksum(content)
+	f.changed = now
+}
+
+// fileObject returns the Object describing the named file.
+func (s *Server) fileObject(name string, f *file) *bunnystorage.Object {
+	return &bunnystorage.Object{
+		UserID:          userID,
+		Path:            s.objectPath(name),
+		ObjectName:      path.Base(name),
+		LastChanged:     f.changed.Format(timeFormat),
+		StorageZoneName: s.StorageZone,
+		Checksum:        f.checksum,
+		DateCreated:     f.created.Format(timeFormat),
+		GUID:            f.guid,
+		Length:          int64(len(f.content)),
+	}
+}
+
+// directoryObject returns the Object describing the named directory.
+func (s *Server) directoryObject(name string, created time.Time) *bunnystorage.Object {
+	return &bunnystorage.Object{
+		UserID:          userID,
+		Path:            s.objectPath(name),
+		ObjectName:      path.Base(name),
+		LastChanged:     created.Format(timeFormat),
+		StorageZoneName: s.StorageZone,
+		DateCreated:     created.Format(timeFormat),
+		GUID:            "00000000-0000-0000-0000-000000000000",
+		IsDirectory:     true,
+	}
+}
+
+// objectPath returns the Path field of the object with the given name, which
+// is the path of its parent directory including the storage zone.
+func (s *Server) objectPath(name string) string {
+	dir := parent(name)
+	if dir == "" {
+		return "/" + s.StorageZone + "/"
+	}
+
+	return "/" + s.StorageZone + "/" + dir + "/"
+}
+
+// parent returns the parent directory of name, or an empty string for objects
+// at the root of the storage zone.
+func parent(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+
+	return dir
+}
+
+// isWithin reports whether name is inside the directory dir.
+func isWithin(name, dir string) bool {
+	return dir == "" || strings.HasPrefix(name, dir+"/")
+}
+
+// checksum returns the uppercase hex-encoded SHA256 hash of content.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeStatus writes a JSON status document in the format used by the API.
+func writeStatus(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write([]byte(`{"HttpCode":` + strconv.Itoa(status) + `,"Message":` + strconv.Quote(message) + `}`))
+}
@@ -0,0 +1,244 @@
+package bunnystoragetest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+func TestServer_Upload(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+	ctx := context.Background()
+
+	content := []byte("Hello, tester!")
+
+	checksum, err := bunnystorage.ComputeSHA256(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("ComputeSHA256() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		filename  string
+		checksum  string
+		wantCode  int
+		wantErrIs error
+	}{
+		{
+			name:     "with_checksum",
+			path:     "/docs/",
+			filename: "hello.txt",
+			checksum: checksum,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "without_checksum",
+			path:     "/",
+			filename: "hello.txt",
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "checksum_mismatch",
+			path:      "/docs/",
+			filename:  "bad.txt",
+			checksum:  strings.Repeat("0", 64),
+			wantCode:  http.StatusBadRequest,
+			wantErrIs: bunnystorage.ErrChecksumMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := client.Upload(ctx, tt.path, tt.filename, tt.checksum, bytes.NewReader(content))
+			if (err != nil) != (tt.wantErrIs != nil) || (tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs)) {
+				t.Fatalf("Upload() error = %v, want %v", err, tt.wantErrIs)
+			}
+
+			if resp.Status != tt.wantCode {
+				t.Errorf("Upload() status = %d, want %d", resp.Status, tt.wantCode)
+			}
+		})
+	}
+
+	t.Cleanup(func() {
+		want := []string{"docs/hello.txt", "hello.txt"}
+
+		if got := srv.Files(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Files() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestServer_Download(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+	ctx := context.Background()
+
+	srv.PutFile("images/logo.svg", []byte("<svg></svg>"))
+
+	body, resp, err := client.Download(ctx, "/images", "logo.svg")
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	if string(body) != "<svg></svg>" {
+		t.Errorf("Download() body = %q, want %q", body, "<svg></svg>")
+	}
+
+	if got := resp.Header.Get("Content-Type"); got != "image/svg+xml" {
+		t.Errorf("Download() Content-Type = %q, want %q", got, "image/svg+xml")
+	}
+
+	_, _, err = client.Download(ctx, "/images", "missing.svg")
+	if !errors.Is(err, bunnystorage.ErrNotFound) {
+		t.Errorf("Download() error = %v, want %v", err, bunnystorage.ErrNotFound)
+	}
+}
+
+func TestServer_List(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+	ctx := context.Background()
+
+	srv.PutFile("a.txt", []byte("a"))
+	srv.PutFile("dir/b.txt", []byte("bb"))
+	srv.PutFile("dir/sub/c.txt", []byte("ccc"))
+
+	tests := []struct {
+		name      string
+		path      string
+		want      []string
+		wantErrIs error
+	}{
+		{
+			name: "root",
+			path: "/",
+			want: []string{"a.txt", "dir/"},
+		},
+		{
+			name: "subdirectory",
+			path: "/dir",
+			want: []string{"b.txt", "sub/"},
+		},
+		{
+			name:      "missing",
+			path:      "/missing",
+			wantErrIs: bunnystorage.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			objects, _, err := client.List(ctx, tt.path)
+			if !errors.Is(err, tt.wantErrIs) {
+				t.Fatalf("List() error = %v, want %v", err, tt.wantErrIs)
+			}
+
+			var got []string
+
+			for _, obj := range objects {
+				name := obj.ObjectName
+				if obj.IsDirectory {
+					name += "/"
+				}
+
+				if obj.StorageZoneName != bunnystoragetest.DefaultStorageZone {
+					t.Errorf("List() StorageZoneName = %q", obj.StorageZoneName)
+				}
+
+				got = append(got, name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Delete(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+	ctx := context.Background()
+
+	srv.PutFile("keep.txt", []byte("keep"))
+	srv.PutFile("dir/a.txt", []byte("a"))
+	srv.PutFile("dir/sub/b.txt", []byte("b"))
+
+	if _, err := client.Delete(ctx, "/dir", "a.txt"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := client.Delete(ctx, "/dir", "a.txt"); !errors.Is(err, bunnystorage.ErrNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, bunnystorage.ErrNotFound)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, srv.URL+"/"+srv.StorageZone+"/dir/", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("AccessKey", srv.Key)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE dir status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if got, want := srv.Files(), []string{"keep.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	t.Parallel()
+
+	srv := bunnystoragetest.NewServer()
+	defer srv.Close()
+
+	cfg := srv.Config()
+	cfg.Key = "wrong-key"
+	cfg.ReadOnlyKey = srv.ReadOnlyKey
+
+	client, err := bunnystorage.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	if _, _, err = client.List(ctx, "/"); err != nil {
+		t.Errorf("List() error = %v, want nil", err)
+	}
+
+	_, err = client.Upload(ctx, "/", "file.txt", "", strings.NewReader("content"))
+	if !errors.Is(err, bunnystorage.ErrUnauthorized) {
+		t.Errorf("Upload() error = %v, want %v", err, bunnystorage.ErrUnauthorized)
+	}
+}
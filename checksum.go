@@ -0,0 +1,174 @@
+package bunnystorage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// WithAutoChecksum makes Upload compute the SHA256 checksum of the body when
+// none is given, so the API can reject corrupted uploads.
+//
+// If the body is an io.ReadSeeker, it is hashed from its current offset and
+// then seeked back before being sent. Otherwise, it is first copied to a
+// temporary file, which is removed once Upload returns.
+func WithAutoChecksum() UploadOption {
+	return func(o *uploadOptions) {
+		o.autoChecksum = true
+	}
+}
+
+// WithVerifyChecksum makes Download and DownloadStream verify the received
+// bytes against the checksum reported for the file by listing its parent
+// directory, which costs one extra request. If the checksums differ, the
+// returned error matches ErrChecksumMismatch. Verification is skipped if the
+// API reports no checksum for the file.
+func WithVerifyChecksum() DownloadOption {
+	return func(o *downloadOptions) {
+		o.verify = true
+	}
+}
+
+// WithExpectedChecksum is like WithVerifyChecksum, but verifies the received
+// bytes against the given hex-encoded SHA256 checksum, such as the Checksum
+// field of an Object obtained from a previous listing, instead of looking it
+// up.
+func WithExpectedChecksum(checksum string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.verify = true
+		o.checksum = checksum
+	}
+}
+
+// checksumBody computes the SHA256 checksum of body and returns it along with
+// the reader to upload in place of body and a function releasing any
+// resources allocated for it.
+func checksumBody(body io.Reader) (checksum string, upload io.Reader, cleanup func(), err error) {
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		offset, seekErr := seeker.Seek(0, io.SeekCurrent)
+		if seekErr == nil {
+			return checksumSeeker(seeker, offset)
+		}
+	}
+
+	return checksumSpool(body)
+}
+
+// checksumSeeker hashes body from offset to its end and seeks it back to
+// offset.
+func checksumSeeker(body io.ReadSeeker, offset int64) (checksum string, upload io.Reader, cleanup func(), err error) {
+	checksum, err = ComputeSHA256(body)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	if _, err = body.Seek(offset, io.SeekStart); err != nil {
+		return "", nil, nil, fmt.Errorf("%w", err)
+	}
+
+	return checksum, body, func() {}, nil
+}
+
+// checksumSpool copies body to a temporary file while hashing it, and returns
+// the file rewound to its start.
+func checksumSpool(body io.Reader) (checksum string, upload io.Reader, cleanup func(), err error) {
+	file, err := os.CreateTemp("", "bunnystorage-upload-*")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%w", err)
+	}
+
+	cleanup = func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}
+
+	hasher := sha256.New()
+
+	if _, err = io.Copy(io.MultiWriter(file, hasher), body); err != nil {
+		cleanup()
+
+		return "", nil, nil, fmt.Errorf("%w", err)
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+
+		return "", nil, nil, fmt.Errorf("%w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), file, cleanup, nil
+}
+
+// expectedChecksum returns the checksum a download must be verified against
+// according to o, or an empty string if it must not be verified.
+func (c *Client) expectedChecksum(ctx context.Context, o *downloadOptions, path, filename string) (string, error) {
+	if !o.verify || o.checksum != "" {
+		return o.checksum, nil
+	}
+
+	obj, err := c.lookup(ctx, path, filename)
+	if err != nil {
+		return "", err
+	}
+
+	return obj.Checksum, nil
+}
+
+// lookup returns the metadata of a file like Stat, without its Response.
+func (c *Client) lookup(ctx context.Context, path, filename string) (*Object, error) {
+	obj, _, err := c.Stat(ctx, path, filename)
+
+	return obj, err
+}
+
+// verifyChecksum returns an error matching ErrChecksumMismatch if the SHA256
+// checksum of content does not match want.
+func verifyChecksum(content []byte, want string) error {
+	sum := sha256.Sum256(content)
+
+	return compareChecksum(hex.EncodeToString(sum[:]), want)
+}
+
+// compareChecksum returns an error matching ErrChecksumMismatch if the
+// hex-encoded checksums got and want differ, ignoring case.
+func compareChecksum(got, want string) error {
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, strings.ToUpper(got), strings.ToUpper(want))
+	}
+
+	return nil
+}
+
+// verifyingReader is an io.ReadCloser that hashes the bytes read through it
+// and fails with ErrChecksumMismatch at the end of the stream if they do not
+// match the expected checksum.
+type verifyingReader struct {
+	rc     io.ReadCloser
+	hasher hash.Hash
+	want   string
+}
+
+// Read implements io.Reader.
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.hasher.Write(p[:n])
+
+	if errors.Is(err, io.EOF) {
+		if verifyErr := compareChecksum(hex.EncodeToString(r.hasher.Sum(nil)), r.want); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is.
+}
+
+// Close implements io.Closer.
+func (r *verifyingReader) Close() error {
+	return r.rc.Close() //nolint:wrapcheck // errors from the wrapped body are returned as is.
+}
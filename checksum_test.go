@@ -0,0 +1,207 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+func TestClient_Upload_AutoChecksum(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("the quick brown fox jumps over the lazy dog")
+
+	tests := []struct {
+		name string
+		body func() io.Reader
+	}{
+		{
+			name: "seeker",
+			body: func() io.Reader {
+				return bytes.NewReader(content)
+			},
+		},
+		{
+			name: "seeker with offset",
+			body: func() io.Reader {
+				r := io.NewSectionReader(bytes.NewReader(append([]byte("skip"), content...)), 0, int64(len(content)+4))
+				_, _ = r.Seek(4, io.SeekStart)
+
+				return r
+			},
+		},
+		{
+			name: "stream",
+			body: func() io.Reader {
+				return io.MultiReader(bytes.NewReader(content))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu       sync.Mutex
+				checksum string
+			)
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			cfg := srv.Config()
+			cfg.Middleware = []bunnystorage.Middleware{
+				func(next http.RoundTripper) http.RoundTripper {
+					return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						mu.Lock()
+						checksum = req.Header.Get("Checksum")
+						mu.Unlock()
+
+						return next.RoundTrip(req)
+					})
+				},
+			}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			if _, err = client.Upload(context.Background(), "/", "fox.txt", "", tt.body(), bunnystorage.WithAutoChecksum()); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			if got, _ := srv.File("fox.txt"); !bytes.Equal(got, content) {
+				t.Errorf("Upload() stored %q, want %q", got, content)
+			}
+
+			sum := sha256.Sum256(content)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if want := strings.ToUpper(hex.EncodeToString(sum[:])); checksum != want {
+				t.Errorf("Upload() Checksum header = %q, want %q", checksum, want)
+			}
+		})
+	}
+}
+
+// corruptDownloads returns a middleware replacing the body of every file
+// download with different content.
+func corruptDownloads() bunnystorage.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil || req.Method != http.MethodGet || strings.HasSuffix(req.URL.Path, "/") {
+				return resp, err
+			}
+
+			content, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+
+			if err != nil {
+				return nil, err
+			}
+
+			content = bytes.ToUpper(content)
+
+			resp.Body = io.NopCloser(bytes.NewReader(content))
+
+			return resp, nil
+		})
+	}
+}
+
+func TestClient_Download_VerifyChecksum(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("the quick brown fox jumps over the lazy dog")
+
+	tests := []struct {
+		name    string
+		opt     bunnystorage.DownloadOption
+		corrupt bool
+		wantErr error
+	}{
+		{
+			name: "listing checksum",
+			opt:  bunnystorage.WithVerifyChecksum(),
+		},
+		{
+			name:    "listing checksum mismatch",
+			opt:     bunnystorage.WithVerifyChecksum(),
+			corrupt: true,
+			wantErr: bunnystorage.ErrChecksumMismatch,
+		},
+		{
+			name:    "expected checksum mismatch",
+			opt:     bunnystorage.WithExpectedChecksum(strings.Repeat("0", 64)),
+			wantErr: bunnystorage.ErrChecksumMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.PutFile("docs/fox.txt", content)
+
+			cfg := srv.Config()
+			if tt.corrupt {
+				cfg.Middleware = []bunnystorage.Middleware{corruptDownloads()}
+			}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			ctx := context.Background()
+
+			_, _, err = client.Download(ctx, "/docs", "fox.txt", tt.opt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Download() error = %v, want %v", err, tt.wantErr)
+			}
+
+			body, _, err := client.DownloadStream(ctx, "/docs", "fox.txt", tt.opt)
+			if err != nil {
+				t.Fatalf("DownloadStream() error = %v", err)
+			}
+			defer body.Close()
+
+			if _, err = io.ReadAll(body); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DownloadStream() read error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_Download_VerifyChecksumNotFound(t *testing.T) {
+	t.Parallel()
+
+	client, _ := bunnystoragetest.NewClient(t)
+
+	_, _, err := client.Download(context.Background(), "/", "missing.txt", bunnystorage.WithVerifyChecksum())
+	if !errors.Is(err, bunnystorage.ErrNotFound) {
+		t.Errorf("Download() error = %v, want %v", err, bunnystorage.ErrNotFound)
+	}
+}
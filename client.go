@@ -3,16 +3,17 @@ package bunnystorage
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"path/filepath"
 	"strings"
 
 	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
-	"git.sr.ht/~jamesponddotco/xstd-go/xnet/xhttp"
 	"git.sr.ht/~jamesponddotco/xstd-go/xstrings"
 )
 
@@ -27,8 +28,16 @@ type (
 		// httpc is the underlying HTTP client used by the API client.
 		httpc *http.Client
 
+		// streamc is the HTTP client used for streaming responses. It shares
+		// the transport of httpc but has no overall timeout, since the caller
+		// controls how long the response body is read for.
+		streamc *http.Client
+
 		// cfg specifies the configuration used by the API client.
 		cfg *Config
+
+		// hedges counts the hedged reads made by the API client.
+		hedges *hedgeCounters
 	}
 )
 
@@ -44,24 +53,31 @@ func NewClient(cfg *Config) (*Client, error) {
 		return nil, err
 	}
 
-	retryPolicy := &xhttp.RetryPolicy{
-		IsRetryable:   xhttp.DefaultIsRetryable,
-		MaxRetries:    cfg.MaxRetries,
-		MinRetryDelay: xhttp.DefaultMinRetryDelay,
-		MaxRetryDelay: xhttp.DefaultMaxRetryDelay,
-	}
+	hedges := &hedgeCounters{}
+	httpc := newHTTPClient(cfg, hedges)
+
+	streamc := *httpc
+	streamc.Timeout = 0
 
 	return &Client{
-		httpc: xhttp.NewRetryingClient(cfg.Timeout, retryPolicy, cfg.Logger),
-		cfg:   cfg,
+		httpc:   httpc,
+		streamc: &streamc,
+		cfg:     cfg,
+		hedges:  hedges,
 	}, nil
 }
 
-// List lists the files in the storage zone.
-func (c *Client) List(ctx context.Context, path string) ([]*Object, *Response, error) {
+// List lists the files and directories in a directory of the storage zone.
+// The objects can be filtered and sorted with the given options.
+func (c *Client) List(ctx context.Context, path string, opts ...ListOption) ([]*Object, *Response, error) {
+	o, err := newListOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	path = strings.TrimPrefix(path, "/")
 
-	uri := xstrings.JoinWithSeparator("/", c.cfg.Endpoint.String(), c.cfg.StorageZone, path+"/")
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, path+"/")
 
 	headers := map[string]string{
 		"Accept":    "application/json",
@@ -75,52 +91,206 @@ func (c *Client) List(ctx context.Context, path string) ([]*Object, *Response, e
 
 	resp, err := c.do(ctx, req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, resp, fmt.Errorf("%w", err)
 	}
 
 	var files []*Object
-	if err := json.Unmarshal(resp.Body, &files); err != nil {
+	if err = json.Unmarshal(resp.Body, &files); err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
-	return files, resp, nil
+	return o.apply(files), resp, nil
 }
 
 // Download downloads a file from the storage zone.
-func (c *Client) Download(ctx context.Context, path, filename string) ([]byte, *Response, error) {
+func (c *Client) Download(ctx context.Context, path, filename string, opts ...DownloadOption) ([]byte, *Response, error) {
+	o := newDownloadOptions(opts)
+	if err := o.validateRange(); err != nil {
+		return nil, nil, err
+	}
+
 	path = strings.TrimPrefix(path, "/")
 	filename = filepath.Base(filename)
 
-	uri := xstrings.JoinWithSeparator("/", c.cfg.Endpoint.String(), c.cfg.StorageZone, path, filename)
+	checksum, err := c.expectedChecksum(ctx, o, "/"+path, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, path, filename)
 
 	headers := map[string]string{
 		"Accept":    "*/*",
 		"AccessKey": c.cfg.AccessKey(OperationRead),
 	}
 
+	if o.ranged {
+		headers["Range"] = o.rangeHeader()
+	}
+
 	req, err := c.request(ctx, http.MethodGet, uri, headers, http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
-	resp, err := c.do(ctx, req)
+	resp, err := c.doWithProgress(ctx, req, o.progress)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%w", err)
+	}
+
+	if checksum != "" {
+		if err = verifyChecksum(resp.Body, checksum); err != nil {
+			return nil, resp, err
+		}
+	}
+
+	return o.trimRange(resp.Status, resp.Body), resp, nil
+}
+
+// DownloadStream downloads a file from the storage zone without buffering it
+// in memory. The caller is responsible for closing the returned body.
+//
+// The returned Response carries the headers and status code, but its Body
+// field is nil unless the API responded with an error. Config.Timeout does not
+// apply to reading the body, so use ctx to bound the transfer instead.
+func (c *Client) DownloadStream(ctx context.Context, path, filename string, opts ...DownloadOption) (io.ReadCloser, *Response, error) {
+	o := newDownloadOptions(opts)
+	if err := o.validateRange(); err != nil {
+		return nil, nil, err
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	filename = filepath.Base(filename)
+
+	checksum, err := c.expectedChecksum(ctx, o, "/"+path, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, path, filename)
+
+	headers := map[string]string{
+		"Accept":    "*/*",
+		"AccessKey": c.cfg.AccessKey(OperationRead),
+	}
+
+	if o.ranged {
+		headers["Range"] = o.rangeHeader()
+	}
+
+	req, err := c.request(ctx, http.MethodGet, uri, headers, http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
-	return resp.Body, resp, nil
+	body, resp, err := c.stream(ctx, req)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%w", err)
+	}
+
+	body, err = o.trimRangeStream(resp.Status, body)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	body = trackDownloadProgress(body, contentLength(resp.Header), o.progress)
+
+	if checksum != "" {
+		body = &verifyingReader{
+			rc:     body,
+			hasher: sha256.New(),
+			want:   checksum,
+		}
+	}
+
+	return body, resp, nil
+}
+
+// Stat returns the metadata of a file in the storage zone without downloading
+// it, as reported by the listing of its parent directory, which includes its
+// checksum, creation date and GUID. The returned Response is the one of the
+// listing. If the file does not exist, the returned error matches ErrNotFound.
+func (c *Client) Stat(ctx context.Context, path, filename string) (*Object, *Response, error) {
+	filename = filepath.Base(filename)
+
+	objects, resp, err := c.List(ctx, path)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%w", err)
+	}
+
+	for _, obj := range objects {
+		if obj.ObjectName == filename && !obj.IsDirectory {
+			return obj, resp, nil
+		}
+	}
+
+	return nil, resp, fmt.Errorf("%s: %w", filename, ErrNotFound)
+}
+
+// Exists reports whether a file exists in the storage zone. Unlike Stat, it
+// sends a single HEAD request for the file instead of listing its parent
+// directory.
+func (c *Client) Exists(ctx context.Context, path, filename string) (bool, error) {
+	path = strings.Trim(path, "/")
+	filename = filepath.Base(filename)
+
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, path, filename)
+
+	headers := map[string]string{
+		"Accept":    "*/*",
+		"AccessKey": c.cfg.AccessKey(OperationRead),
+	}
+
+	req, err := c.request(ctx, http.MethodHead, uri, headers, http.NoBody)
+	if err != nil {
+		return false, fmt.Errorf("%w", err)
+	}
+
+	if _, err = c.do(ctx, req); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%w", err)
+	}
+
+	return true, nil
 }
 
 // Upload uploads a file to the storage zone.
-func (c *Client) Upload(ctx context.Context, path, filename, checksum string, body io.Reader) (*Response, error) {
+//
+// If body is an io.Seeker, only the bytes from its current offset to its end
+// are sent, and their number is used as the Content-Length of the request.
+func (c *Client) Upload(ctx context.Context, path, filename, checksum string, body io.Reader, opts ...UploadOption) (*Response, error) {
+	return c.upload(ctx, path, filename, checksum, body, -1, opts...)
+}
+
+// upload implements Upload. If length is not negative, it is the number of
+// bytes in body and is sent as the Content-Length of the request.
+func (c *Client) upload(ctx context.Context, path, filename, checksum string, body io.Reader, length int64, opts ...UploadOption) (*Response, error) {
+	o := newUploadOptions(opts)
+
 	path = strings.TrimPrefix(path, "/")
 
-	uri := xstrings.JoinWithSeparator("/", c.cfg.Endpoint.String(), c.cfg.StorageZone, path, filename)
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, path, filename)
 
 	headers := map[string]string{
 		"AccessKey": c.cfg.AccessKey(OperationWrite),
 	}
 
+	if checksum == "" && o.autoChecksum {
+		var (
+			cleanup func()
+			err     error
+		)
+
+		checksum, body, cleanup, err = checksumBody(body)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+	}
+
 	if checksum != "" {
 		headers["Checksum"] = strings.ToUpper(checksum)
 	}
@@ -130,20 +300,40 @@ func (c *Client) Upload(ctx context.Context, path, filename, checksum string, bo
 		return nil, fmt.Errorf("%w", err)
 	}
 
+	if req.ContentLength == 0 && req.Body != http.NoBody {
+		if length < 0 {
+			length = seekerSize(body)
+		}
+
+		switch {
+		case length == 0:
+			req.Body = http.NoBody
+		case length > 0:
+			req.ContentLength = length
+		}
+	}
+
+	makeRewindable(req, body)
+
+	if o.progress != nil {
+		trackUploadProgress(req, o.progress)
+	}
+
 	resp, err := c.do(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		return resp, fmt.Errorf("%w", err)
 	}
 
 	return resp, nil
 }
 
-// Delete deletes a file from the storage zone.
+// Delete deletes a file from the storage zone. Use DeleteDirectory to delete
+// a directory.
 func (c *Client) Delete(ctx context.Context, path, filename string) (*Response, error) {
 	path = strings.TrimPrefix(path, "/")
 	filename = filepath.Base(filename)
 
-	uri := xstrings.JoinWithSeparator("/", c.cfg.Endpoint.String(), c.cfg.StorageZone, path, filename)
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, path, filename)
 
 	headers := map[string]string{
 		"AccessKey": c.cfg.AccessKey(OperationWrite),
@@ -156,22 +346,59 @@ func (c *Client) Delete(ctx context.Context, path, filename string) (*Response,
 
 	resp, err := c.do(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		return resp, fmt.Errorf("%w", err)
 	}
 
 	return resp, nil
 }
 
-// do performs an HTTP request using the underlying HTTP client.
-func (c *Client) do(_ context.Context, req *http.Request) (*Response, error) {
+// do performs an HTTP request using the underlying HTTP client. If the API
+// responds with a non-2xx status code, the response is returned alongside an
+// *APIError.
+func (c *Client) do(ctx context.Context, req *http.Request) (*Response, error) {
+	return c.doWithProgress(ctx, req, nil)
+}
+
+// doWithProgress is like do, but reports the progress of reading a successful
+// response body to fn if it is not nil.
+//
+// Failing to drain or close the response body never aborts the process: the
+// error is joined into the returned error if the call failed anyway, or logged
+// through Config.Logger otherwise, since the response is complete.
+func (c *Client) doWithProgress(ctx context.Context, req *http.Request, fn ProgressFunc) (resp *Response, err error) {
 	ret, err := c.httpc.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
 
+	var readErr error
+
 	defer func() {
-		if err = xhttp.DrainResponseBody(ret); err != nil {
-			log.Fatal(err)
+		var closeErr error
+
+		// There is no point draining a body that already failed to be read.
+		if readErr == nil {
+			closeErr = closeBody(ret.Body)
+		} else if bodyErr := ret.Body.Close(); bodyErr != nil {
+			closeErr = fmt.Errorf("close response body: %w", bodyErr)
+		}
+
+		if closeErr == nil {
+			return
+		}
+
+		if err != nil {
+			err = errors.Join(err, closeErr)
+
+			return
+		}
+
+		if c.cfg.Logger != nil {
+			c.cfg.Logger.LogAttrs(ctx, slog.LevelWarn, "failed to close response body",
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.String("error", closeErr.Error()),
+			)
 		}
 	}()
 
@@ -183,9 +410,13 @@ func (c *Client) do(_ context.Context, req *http.Request) (*Response, error) {
 		buffer = bytes.NewBuffer(make([]byte, 0))
 	}
 
-	_, err = io.Copy(buffer, ret.Body)
-	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+	var body io.Reader = ret.Body
+	if isSuccess(ret.StatusCode) {
+		body = trackDownloadProgress(ret.Body, ret.ContentLength, fn)
+	}
+
+	if _, readErr = io.Copy(buffer, body); readErr != nil {
+		return nil, fmt.Errorf("%w", readErr)
 	}
 
 	response := &Response{
@@ -194,9 +425,55 @@ func (c *Client) do(_ context.Context, req *http.Request) (*Response, error) {
 		Status: ret.StatusCode,
 	}
 
+	if !isSuccess(ret.StatusCode) {
+		return response, newAPIError(req, ret.StatusCode, response.Body)
+	}
+
 	return response, nil
 }
 
+// stream performs an HTTP request using the underlying streaming HTTP client
+// and returns the response body unread. If the API responds with a non-2xx
+// status code, the body is consumed and closed and an *APIError is returned.
+func (c *Client) stream(_ context.Context, req *http.Request) (io.ReadCloser, *Response, error) {
+	ret, err := c.streamc.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w", err)
+	}
+
+	response := &Response{
+		Header: ret.Header.Clone(),
+		Status: ret.StatusCode,
+	}
+
+	if !isSuccess(ret.StatusCode) {
+		var body []byte
+
+		body, err = io.ReadAll(io.LimitReader(ret.Body, maxErrorBodySize))
+		if err != nil {
+			// There is no point draining a body that already failed to be
+			// read.
+			if bodyErr := ret.Body.Close(); bodyErr != nil {
+				err = errors.Join(err, fmt.Errorf("close response body: %w", bodyErr))
+			}
+
+			return nil, response, fmt.Errorf("%w", err)
+		}
+
+		response.Body = body
+
+		apiErr := newAPIError(req, ret.StatusCode, body)
+
+		if closeErr := closeBody(ret.Body); closeErr != nil {
+			return nil, response, errors.Join(apiErr, closeErr)
+		}
+
+		return nil, response, apiErr
+	}
+
+	return ret.Body, response, nil
+}
+
 // request is a convenience function for creating an HTTP request.
 func (c *Client) request(ctx context.Context, method, uri string, headers map[string]string, body io.Reader) (*http.Request, error) {
 	if headers == nil {
@@ -1,14 +1,22 @@
 package bunnystorage_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
 	"git.sr.ht/~jamesponddotco/bunnystorage-go/internal/testutil"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
 )
 
 const _testDataPath string = "tests/testdata"
@@ -67,12 +75,13 @@ func TestClient_List(t *testing.T) {
 	})
 
 	tests := []struct {
-		name    string
-		handler http.HandlerFunc
-		route   string
-		path    string
-		want    []*bunnystorage.Object
-		wantErr bool
+		name      string
+		handler   http.HandlerFunc
+		route     string
+		path      string
+		want      []*bunnystorage.Object
+		wantErr   bool
+		wantErrIs error
 	}{
 		{
 			name: "valid_response",
@@ -139,6 +148,23 @@ func TestClient_List(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "unauthorized_response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+
+				_, err := w.Write([]byte(`{"HttpCode":401,"Message":"Unauthorized"}`))
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+			},
+			route:     "/mock/unauthorized/",
+			path:      "/unauthorized",
+			want:      nil,
+			wantErr:   true,
+			wantErrIs: bunnystorage.ErrUnauthorized,
+		},
 	}
 
 	for _, tt := range tests {
@@ -151,6 +177,10 @@ func TestClient_List(t *testing.T) {
 				return
 			}
 
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("List() error = %v, want %v", err, tt.wantErrIs)
+			}
+
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("List() got = %v, want %v", got, tt.want)
 			}
@@ -170,14 +200,15 @@ func TestClient_Download(t *testing.T) {
 	})
 
 	tests := []struct {
-		name     string
-		handler  http.HandlerFunc
-		route    string
-		path     string
-		filename string
-		want     []byte
-		wantCode int
-		wantErr  bool
+		name      string
+		handler   http.HandlerFunc
+		route     string
+		path      string
+		filename  string
+		want      []byte
+		wantCode  int
+		wantErr   bool
+		wantErrIs error
 	}{
 		{
 			name: "valid_response",
@@ -209,12 +240,13 @@ func TestClient_Download(t *testing.T) {
 
 				w.WriteHeader(http.StatusNotFound)
 			},
-			route:    "/mock/testdata/download-not-found.json",
-			path:     "/testdata",
-			filename: "download-not-found.json",
-			want:     nil,
-			wantCode: http.StatusNotFound,
-			wantErr:  false,
+			route:     "/mock/testdata/download-not-found.json",
+			path:      "/testdata",
+			filename:  "download-not-found.json",
+			want:      nil,
+			wantCode:  http.StatusNotFound,
+			wantErr:   true,
+			wantErrIs: bunnystorage.ErrNotFound,
 		},
 	}
 
@@ -228,6 +260,10 @@ func TestClient_Download(t *testing.T) {
 				return
 			}
 
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("Download() error = %v, want %v", err, tt.wantErrIs)
+			}
+
 			if len(got) != len(tt.want) {
 				t.Errorf("Download() got = %v, want %v", len(got), len(tt.want))
 			}
@@ -239,6 +275,264 @@ func TestClient_Download(t *testing.T) {
 	}
 }
 
+func TestClient_DownloadStream(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+		ctx           = context.Background()
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		route    string
+		path     string
+		filename string
+		want     []byte
+		wantType string
+		wantCode int
+		wantErr  bool
+	}{
+		{
+			name: "valid_response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("DownloadStream() method = %v, want %v", r.Method, http.MethodGet)
+				}
+
+				w.Header().Set("Content-Type", "image/jpeg")
+
+				_, err := w.Write(testutil.ReadFile(t, _testDataPath+"/download-valid.jpg"))
+				if err != nil {
+					t.Fatalf("DownloadStream() error = %v", err)
+				}
+			},
+			route:    "/mock/testdata/stream-valid.jpg",
+			path:     "/testdata",
+			filename: "stream-valid.jpg",
+			want:     testutil.ReadFile(t, _testDataPath+"/download-valid.jpg"),
+			wantType: "image/jpeg",
+			wantCode: http.StatusOK,
+			wantErr:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux.HandleFunc(tt.route, tt.handler)
+
+			body, resp, err := client.DownloadStream(ctx, tt.path, tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DownloadStream() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			defer body.Close()
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("DownloadStream() got %d bytes, want %d", len(got), len(tt.want))
+			}
+
+			if resp.Body != nil {
+				t.Errorf("DownloadStream() resp.Body = %v, want nil", resp.Body)
+			}
+
+			if resp.Header.Get("Content-Type") != tt.wantType {
+				t.Errorf("DownloadStream() Content-Type = %v, want %v", resp.Header.Get("Content-Type"), tt.wantType)
+			}
+
+			if resp.Status != tt.wantCode {
+				t.Errorf("DownloadStream() got = %v, want %v", resp.Status, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestClient_Stat(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+		ctx           = context.Background()
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		route     string
+		path      string
+		filename  string
+		want      *bunnystorage.Object
+		wantErr   bool
+		wantErrIs error
+	}{
+		{
+			name: "valid_response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("Stat() method = %v, want %v", r.Method, http.MethodGet)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+
+				_, err := w.Write(testutil.ReadFile(t, _testDataPath+"/list-valid.json"))
+				if err != nil {
+					t.Fatalf("Stat() error = %v", err)
+				}
+			},
+			route:    "/mock/testdata/",
+			path:     "/testdata",
+			filename: "87dwda.txt",
+			want: &bunnystorage.Object{
+				UserID:          "737ccef2-ded9-4acf-9f2f-ce006c7a3bd1",
+				Path:            "/bunnystorage-go/testdata/",
+				ObjectName:      "87dwda.txt",
+				LastChanged:     "2023-04-20T15:32:08.535",
+				StorageZoneName: "bunnystorage-go",
+				Checksum:        "27307E0E4EBCA9D816582BBB6EEFB0FD6F892A4412398345408B7068AC236BDE",
+				DateCreated:     "2023-04-20T15:32:08.535",
+				GUID:            "6c5cf3ad-be04-4269-a0cb-43c05b155423",
+				Length:          65,
+				ServerID:        272,
+				StorageZoneID:   996873,
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing_file",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				_, err := w.Write(testutil.ReadFile(t, _testDataPath+"/list-valid.json"))
+				if err != nil {
+					t.Fatalf("Stat() error = %v", err)
+				}
+			},
+			route:     "/mock/missing/",
+			path:      "/missing",
+			filename:  "stat-not-found.jpg",
+			want:      nil,
+			wantErr:   true,
+			wantErrIs: bunnystorage.ErrNotFound,
+		},
+		{
+			name: "not_found_response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			route:     "/mock/not-found/",
+			path:      "/not-found",
+			filename:  "stat-not-found.jpg",
+			want:      nil,
+			wantErr:   true,
+			wantErrIs: bunnystorage.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux.HandleFunc(tt.route, tt.handler)
+
+			got, _, err := client.Stat(ctx, tt.path, tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Stat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("Stat() error = %v, want %v", err, tt.wantErrIs)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stat() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Exists(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+		ctx           = context.Background()
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		route    string
+		path     string
+		filename string
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name: "existing_file",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			route:    "/mock/testdata/exists.txt",
+			path:     "/testdata",
+			filename: "exists.txt",
+			want:     true,
+			wantErr:  false,
+		},
+		{
+			name: "missing_file",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			route:    "/mock/testdata/missing.txt",
+			path:     "/testdata",
+			filename: "missing.txt",
+			want:     false,
+			wantErr:  false,
+		},
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			route:    "/mock/testdata/unauthorized.txt",
+			path:     "/testdata",
+			filename: "unauthorized.txt",
+			want:     false,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux.HandleFunc(tt.route, tt.handler)
+
+			got, err := client.Exists(ctx, tt.path, tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Exists() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Exists() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestClient_Upload(t *testing.T) {
 	var (
 		client        = testutil.SetupMockClient(t)
@@ -317,13 +611,14 @@ func TestClient_Delete(t *testing.T) {
 	})
 
 	tests := []struct {
-		name     string
-		handler  http.HandlerFunc
-		route    string
-		path     string
-		filename string
-		wantCode int
-		wantErr  bool
+		name      string
+		handler   http.HandlerFunc
+		route     string
+		path      string
+		filename  string
+		wantCode  int
+		wantErr   bool
+		wantErrIs error
 	}{
 		{
 			name: "valid_response",
@@ -349,11 +644,12 @@ func TestClient_Delete(t *testing.T) {
 
 				w.WriteHeader(http.StatusNotFound)
 			},
-			route:    "/mock/testdata/delete-not-found.json",
-			path:     "/testdata",
-			filename: "delete-not-found.json",
-			wantCode: http.StatusNotFound,
-			wantErr:  false,
+			route:     "/mock/testdata/delete-not-found.json",
+			path:      "/testdata",
+			filename:  "delete-not-found.json",
+			wantCode:  http.StatusNotFound,
+			wantErr:   true,
+			wantErrIs: bunnystorage.ErrNotFound,
 		},
 	}
 
@@ -367,9 +663,132 @@ func TestClient_Delete(t *testing.T) {
 				return
 			}
 
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("Delete() error = %v, want %v", err, tt.wantErrIs)
+			}
+
 			if resp.Status != tt.wantCode {
 				t.Errorf("Delete() got = %v, want %v", resp.Status, tt.wantCode)
 			}
 		})
 	}
 }
+
+func TestClient_TruncatedBody(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Promise more bytes than are sent, so the connection is closed in
+		// the middle of the body.
+		w.Header().Set("Content-Length", "1024")
+		w.WriteHeader(http.StatusOK)
+
+		_, _ = w.Write([]byte("partial"))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := bunnystorage.NewClient(&bunnystorage.Config{
+		StorageZone: "zone",
+		Key:         "key",
+		BaseURL:     srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	if _, _, err = client.Download(ctx, "/", "file.txt"); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Download() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	if _, _, err = client.List(ctx, "/"); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("List() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+// errClose is returned by the bodies of failingCloseBodies.
+const errClose xerrors.Error = "close failed"
+
+// failingCloseBodies returns a middleware making every response body fail to
+// close.
+func failingCloseBodies() bunnystorage.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			body := resp.Body
+
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: body,
+				Closer: closerFunc(func() error {
+					_ = body.Close()
+
+					return errClose
+				}),
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+// closerFunc is an adapter to allow the use of ordinary functions as
+// io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestClient_BodyCloseError(t *testing.T) {
+	t.Parallel()
+
+	var logs logBuffer
+
+	srv := bunnystoragetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.PutFile("file.txt", []byte("content"))
+
+	cfg := srv.Config()
+	cfg.Middleware = []bunnystorage.Middleware{failingCloseBodies()}
+	cfg.Logger = newLogger(&logs, slog.LevelWarn)
+
+	client, err := bunnystorage.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	// A complete response is still returned, and the error is logged.
+	body, _, err := client.Download(ctx, "/", "file.txt")
+	if err != nil || string(body) != "content" {
+		t.Fatalf("Download() = %q, %v, want %q, nil", body, err, "content")
+	}
+
+	if !strings.Contains(logs.String(), errClose.Error()) {
+		t.Errorf("logs = %q, want them to mention %q", logs.String(), errClose)
+	}
+
+	// A failed call reports both errors.
+	_, _, err = client.Download(ctx, "/", "missing.txt")
+	if !errors.Is(err, bunnystorage.ErrNotFound) || !errors.Is(err, errClose) {
+		t.Errorf("Download() error = %v, want %v and %v", err, bunnystorage.ErrNotFound, errClose)
+	}
+
+	// So does a failed stream.
+	_, _, err = client.DownloadStream(ctx, "/", "missing.txt")
+
+	var apiErr *bunnystorage.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, errClose) {
+		t.Errorf("DownloadStream() error = %v, want an *APIError and %v", err, errClose)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// Exit codes returned by run.
+const (
+	exitSuccess int = 0
+	exitFailure int = 1
+	exitUsage   int = 2
+)
+
+const (
+	// errUsage is returned when the command line is invalid.
+	errUsage xerrors.Error = "invalid usage"
+
+	// errUnknownCommand is returned when the command does not exist.
+	errUnknownCommand xerrors.Error = "unknown command"
+)
+
+// usage is the help text printed for invalid command lines.
+const usage string = `Usage: bunnystorage [flags] <command> [arguments]
+
+Commands:
+  ls [path]             list the contents of a directory
+  tree [path]           list the contents of a directory recursively
+  stat <path>           show the metadata of a file
+  cat <path>            write the contents of a file to standard output
+  get <path> [local]    download a file, without overwriting local files
+  put <local> <path>    upload a file
+  rm <path>             delete a file, or a directory if path ends with /
+
+Flags:
+`
+
+// command is a subcommand of the tool.
+type command struct {
+	run     func(a *app, ctx context.Context, args []string) error
+	minArgs int
+	maxArgs int
+}
+
+// commands returns the subcommands of the tool, keyed by name.
+func commands() map[string]command {
+	return map[string]command{
+		"ls":   {run: (*app).list, minArgs: 0, maxArgs: 1},
+		"tree": {run: (*app).tree, minArgs: 0, maxArgs: 1},
+		"stat": {run: (*app).stat, minArgs: 1, maxArgs: 1},
+		"cat":  {run: (*app).cat, minArgs: 1, maxArgs: 1},
+		"get":  {run: (*app).get, minArgs: 1, maxArgs: 2},
+		"put":  {run: (*app).put, minArgs: 2, maxArgs: 2},
+		"rm":   {run: (*app).remove, minArgs: 1, maxArgs: 1},
+	}
+}
+
+// app holds the state shared by the subcommands.
+type app struct {
+	client *bunnystorage.Client
+	stdout io.Writer
+	json   bool
+	resume bool
+}
+
+// run runs the tool with the given arguments and environment, and returns its
+// exit code.
+func run(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bunnystorage", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	var (
+		zone     = flags.String("zone", getenv("BUNNY_STORAGE_ZONE"), "storage zone `name` (BUNNY_STORAGE_ZONE)")
+		key      = flags.String("key", getenv("BUNNY_WRITE_API_KEY"), "read-write API `key` (BUNNY_WRITE_API_KEY)")
+		readKey  = flags.String("read-key", getenv("BUNNY_READ_API_KEY"), "read-only API `key` (BUNNY_READ_API_KEY)")
+		endpoint = flags.String("endpoint", getenv("BUNNY_ENDPOINT"), "endpoint host or base `URL` (BUNNY_ENDPOINT)")
+		asJSON   = flags.Bool("json", false, "print output as JSON")
+		resume   = flags.Bool("continue", false, "resume an interrupted get from its .part file")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	name := flags.Arg(0)
+
+	cmd, ok := commands()[name]
+	if !ok {
+		fmt.Fprintf(stderr, "bunnystorage: %v: %s\n", errUnknownCommand, name)
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	cmdArgs := flags.Args()[1:]
+	if len(cmdArgs) < cmd.minArgs || len(cmdArgs) > cmd.maxArgs {
+		fmt.Fprintf(stderr, "bunnystorage: %v: wrong number of arguments for %s\n", errUsage, name)
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	cfg, err := newConfig(*zone, *key, *readKey, *endpoint)
+	if err != nil {
+		fmt.Fprintf(stderr, "bunnystorage: %v\n", err)
+
+		return exitUsage
+	}
+
+	client, err := bunnystorage.NewClient(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "bunnystorage: %v\n", err)
+
+		return exitUsage
+	}
+
+	a := &app{
+		client: client,
+		stdout: stdout,
+		json:   *asJSON,
+		resume: *resume,
+	}
+
+	if err = cmd.run(a, ctx, cmdArgs); err != nil {
+		fmt.Fprintf(stderr, "bunnystorage: %s: %v\n", name, err)
+
+		return exitFailure
+	}
+
+	return exitSuccess
+}
+
+// newConfig returns the client configuration for the given flag values. The
+// endpoint may be the host or URL of a known endpoint, or any other base URL.
+// If only a read-only key is given, it is used for every request.
+func newConfig(zone, key, readKey, endpoint string) (*bunnystorage.Config, error) {
+	if key == "" {
+		key = readKey
+	}
+
+	cfg := &bunnystorage.Config{
+		StorageZone: zone,
+		Key:         key,
+		ReadOnlyKey: readKey,
+		Endpoint:    bunnystorage.EndpointFalkenstein,
+	}
+
+	if endpoint == "" {
+		return cfg, nil
+	}
+
+	parsed, err := bunnystorage.Parse(endpoint)
+	if err == nil {
+		cfg.Endpoint = parsed
+
+		return cfg, nil
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	cfg.BaseURL = endpoint
+
+	return cfg, nil
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	srv := bunnystoragetest.NewServer()
+	defer srv.Close()
+
+	srv.PutFile("docs/readme.txt", []byte("Hello, tester!"))
+	srv.PutFile("docs/sub/deep.txt", []byte("deep"))
+	srv.PutFile("top.txt", []byte("top"))
+
+	env := map[string]string{
+		"BUNNY_STORAGE_ZONE":  srv.StorageZone,
+		"BUNNY_WRITE_API_KEY": srv.Key,
+		"BUNNY_READ_API_KEY":  srv.ReadOnlyKey,
+		"BUNNY_ENDPOINT":      srv.URL,
+	}
+
+	getenv := func(key string) string {
+		return env[key]
+	}
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "upload.txt")
+
+	if err := os.WriteFile(local, []byte("uploaded"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+	}{
+		{
+			name:       "ls",
+			args:       []string{"ls", "/docs"},
+			wantCode:   exitSuccess,
+			wantStdout: []string{"NAME", "readme.txt", "14", "sub/"},
+		},
+		{
+			name:       "tree",
+			args:       []string{"tree"},
+			wantCode:   exitSuccess,
+			wantStdout: []string{"./\n  docs/\n    readme.txt\n    sub/\n      deep.txt\n  top.txt\n"},
+		},
+		{
+			name:       "stat",
+			args:       []string{"stat", "docs/readme.txt"},
+			wantCode:   exitSuccess,
+			wantStdout: []string{"readme.txt", "Size:", "14"},
+		},
+		{
+			name:       "cat",
+			args:       []string{"cat", "/top.txt"},
+			wantCode:   exitSuccess,
+			wantStdout: []string{"top"},
+		},
+		{
+			name:     "put",
+			args:     []string{"put", local, "/uploads/"},
+			wantCode: exitSuccess,
+		},
+		{
+			name:     "get",
+			args:     []string{"get", "/docs/sub/deep.txt", filepath.Join(dir, "deep.txt")},
+			wantCode: exitSuccess,
+		},
+		{
+			name:     "rm",
+			args:     []string{"rm", "/top.txt"},
+			wantCode: exitSuccess,
+		},
+		{
+			name:     "rm missing",
+			args:     []string{"rm", "/missing.txt"},
+			wantCode: exitFailure,
+		},
+		{
+			name:       "rm directory",
+			args:       []string{"rm", "/docs/sub/"},
+			wantCode:   exitSuccess,
+			wantStdout: []string{"docs/sub/\ndocs/sub/deep.txt\n"},
+		},
+		{
+			name:     "rm root",
+			args:     []string{"rm", "/"},
+			wantCode: exitFailure,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"mv", "a", "b"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "missing arguments",
+			args:     []string{"put", local},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "no command",
+			args:     []string{},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(context.Background(), tt.args, getenv, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want it to contain %q", stdout.String(), want)
+				}
+			}
+		})
+	}
+
+	if got, ok := srv.File("uploads/upload.txt"); !ok || string(got) != "uploaded" {
+		t.Errorf("put uploaded %q, want %q", got, "uploaded")
+	}
+
+	if got, err := os.ReadFile(filepath.Join(dir, "deep.txt")); err != nil || string(got) != "deep" {
+		t.Errorf("get wrote %q, %v, want %q", got, err, "deep")
+	}
+
+	if _, ok := srv.File("top.txt"); ok {
+		t.Error("rm did not delete top.txt")
+	}
+
+	if _, ok := srv.File("docs/sub/deep.txt"); ok {
+		t.Error("rm did not delete docs/sub/")
+	}
+
+	if _, ok := srv.File("docs/readme.txt"); !ok {
+		t.Error("rm deleted docs/readme.txt")
+	}
+}
+
+func TestRun_JSON(t *testing.T) {
+	t.Parallel()
+
+	srv := bunnystoragetest.NewServer()
+	defer srv.Close()
+
+	srv.PutFile("a.txt", []byte("a"))
+	srv.PutFile("dir/b.txt", []byte("bb"))
+
+	args := []string{
+		"-zone", srv.StorageZone,
+		"-key", srv.Key,
+		"-endpoint", srv.URL,
+		"-json",
+		"tree",
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), args, func(string) string { return "" }, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+
+	var objects []*bunnystorage.Object
+	if err := json.Unmarshal(stdout.Bytes(), &objects); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.ObjectName)
+	}
+
+	if got, want := strings.Join(names, ","), "a.txt,dir,b.txt"; got != want {
+		t.Errorf("tree objects = %s, want %s", got, want)
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		key, readKey string
+		wantEndpoint bunnystorage.Endpoint
+		wantBaseURL  string
+		wantKey      string
+		wantErr      bool
+	}{
+		{
+			name:         "default",
+			key:          "key",
+			wantEndpoint: bunnystorage.EndpointFalkenstein,
+			wantKey:      "key",
+		},
+		{
+			name:         "known host",
+			endpoint:     "ny.storage.bunnycdn.com",
+			key:          "key",
+			wantEndpoint: bunnystorage.EndpointNewYork,
+			wantKey:      "key",
+		},
+		{
+			name:         "base URL",
+			endpoint:     "http://127.0.0.1:8080",
+			readKey:      "read-key",
+			wantEndpoint: bunnystorage.EndpointFalkenstein,
+			wantBaseURL:  "http://127.0.0.1:8080",
+			wantKey:      "read-key",
+		},
+		{
+			name:     "unknown host",
+			endpoint: "typo.storage.bunnycdn.com",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := newConfig("zone", tt.key, tt.readKey, tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if cfg.Endpoint != tt.wantEndpoint || cfg.BaseURL != tt.wantBaseURL || cfg.Key != tt.wantKey {
+				t.Errorf("newConfig() = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestRun_Get(t *testing.T) {
+	t.Parallel()
+
+	srv := bunnystoragetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.PutFile("notes.txt", []byte("remote notes"))
+
+	getenv := func(key string) string {
+		return map[string]string{
+			"BUNNY_STORAGE_ZONE":  srv.StorageZone,
+			"BUNNY_WRITE_API_KEY": srv.Key,
+			"BUNNY_ENDPOINT":      srv.URL,
+		}[key]
+	}
+
+	tests := []struct {
+		name     string
+		flags    []string
+		existing string
+		partial  string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "new file",
+			wantCode: exitSuccess,
+			want:     "remote notes",
+		},
+		{
+			name:     "existing file",
+			existing: "my local notes",
+			wantCode: exitFailure,
+			want:     "my local notes",
+		},
+		{
+			name:     "stale partial file",
+			partial:  "remote XXXXX",
+			wantCode: exitSuccess,
+			want:     "remote notes",
+		},
+		{
+			name:     "resumed partial file",
+			flags:    []string{"-continue"},
+			partial:  "remote",
+			wantCode: exitSuccess,
+			want:     "remote notes",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			local := filepath.Join(t.TempDir(), "notes.txt")
+
+			if tt.existing != "" {
+				if err := os.WriteFile(local, []byte(tt.existing), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.partial != "" {
+				if err := os.WriteFile(local+bunnystorage.PartialFileSuffix, []byte(tt.partial), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+
+			args := append(append([]string(nil), tt.flags...), "get", "/notes.txt", local)
+
+			if code := run(context.Background(), args, getenv, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			got, err := os.ReadFile(local)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("local file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// list implements the ls command.
+func (a *app) list(ctx context.Context, args []string) error {
+	dir := "/"
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	objects, _, err := a.client.List(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].ObjectName < objects[j].ObjectName
+	})
+
+	if a.json {
+		return a.printJSON(objects)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "NAME\tSIZE\tLAST CHANGED")
+
+	for _, obj := range objects {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", displayName(obj), displaySize(obj), obj.LastChanged)
+	}
+
+	return flush(w)
+}
+
+// tree implements the tree command.
+func (a *app) tree(ctx context.Context, args []string) error {
+	root := "/"
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	var objects []*bunnystorage.Object
+
+	err := a.client.Walk(ctx, root, func(name string, obj *bunnystorage.Object, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if a.json {
+			if obj.ObjectName != "" || !obj.IsDirectory {
+				objects = append(objects, obj)
+			}
+
+			return nil
+		}
+
+		depth := 0
+		if name != "." {
+			depth = strings.Count(name, "/") + 1
+		}
+
+		fmt.Fprintf(a.stdout, "%s%s\n", strings.Repeat("  ", depth), displayName(obj))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if a.json {
+		return a.printJSON(objects)
+	}
+
+	return nil
+}
+
+// stat implements the stat command.
+func (a *app) stat(ctx context.Context, args []string) error {
+	dir, name := splitRemote(args[0])
+
+	obj, _, err := a.client.Stat(ctx, dir, name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if a.json {
+		return a.printJSON(obj)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", obj.ObjectName)
+	fmt.Fprintf(w, "Path:\t%s\n", obj.Path)
+	fmt.Fprintf(w, "Size:\t%d\n", obj.Length)
+	fmt.Fprintf(w, "Content type:\t%s\n", obj.ContentType)
+	fmt.Fprintf(w, "Last changed:\t%s\n", obj.LastChanged)
+	fmt.Fprintf(w, "Checksum:\t%s\n", obj.Checksum)
+
+	return flush(w)
+}
+
+// cat implements the cat command.
+func (a *app) cat(ctx context.Context, args []string) error {
+	dir, name := splitRemote(args[0])
+
+	body, _, err := a.client.DownloadStream(ctx, dir, name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer body.Close()
+
+	if _, err = io.Copy(a.stdout, body); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// get implements the get command. It refuses to overwrite an existing local
+// file, and resumes an interrupted download only if the -continue flag is set.
+func (a *app) get(ctx context.Context, args []string) error {
+	dir, name := splitRemote(args[0])
+
+	local := name
+	if len(args) > 1 {
+		local = args[1]
+	}
+
+	if !a.resume {
+		err := os.Remove(local + bunnystorage.PartialFileSuffix)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	if _, err := a.client.DownloadFile(ctx, dir, name, local, bunnystorage.WithNoOverwrite()); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// put implements the put command. If the remote path ends with a slash, the
+// file keeps its local name inside that directory.
+func (a *app) put(ctx context.Context, args []string) error {
+	local, remote := args[0], args[1]
+
+	if strings.HasSuffix(remote, "/") {
+		remote += filepath.Base(local)
+	}
+
+	dir, name := splitRemote(remote)
+
+	file, err := os.Open(local)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer file.Close()
+
+	if _, err = a.client.Upload(ctx, dir, name, "", file, bunnystorage.WithAutoChecksum()); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// remove implements the rm command. If the path ends with a slash, the
+// directory is deleted recursively and every deleted object is printed.
+func (a *app) remove(ctx context.Context, args []string) error {
+	if strings.HasSuffix(args[0], "/") {
+		result, err := a.client.DeleteDirectory(ctx, args[0], bunnystorage.WithRecursive())
+		if result != nil {
+			for _, name := range result.Deleted {
+				fmt.Fprintln(a.stdout, name)
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	}
+
+	dir, name := splitRemote(args[0])
+
+	if _, err := a.client.Delete(ctx, dir, name); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// printJSON writes v to standard output as indented JSON.
+func (a *app) printJSON(v any) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// splitRemote splits a remote file path into its directory and file name.
+func splitRemote(p string) (dir, name string) {
+	dir, name = path.Split(strings.TrimPrefix(p, "/"))
+	if dir == "" {
+		dir = "/"
+	}
+
+	return dir, name
+}
+
+// displayName returns the name of the object, with a trailing slash for
+// directories.
+func displayName(obj *bunnystorage.Object) string {
+	name := obj.ObjectName
+	if name == "" {
+		name = "."
+	}
+
+	if obj.IsDirectory {
+		return name + "/"
+	}
+
+	return name
+}
+
+// displaySize returns the size of the object, or a dash for directories.
+func displaySize(obj *bunnystorage.Object) string {
+	if obj.IsDirectory {
+		return "-"
+	}
+
+	return strconv.FormatInt(obj.Length, 10)
+}
+
+// flush flushes the tabwriter.
+func flush(w *tabwriter.Writer) error {
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// Command bunnystorage is a command-line client for bunny.net Edge Storage
+// zones.
+//
+// Usage:
+//
+//	bunnystorage [flags] <command> [arguments]
+//
+// The commands are:
+//
+//	ls [path]                list the contents of a directory
+//	tree [path]              list the contents of a directory recursively
+//	stat <path>              show the metadata of a file
+//	cat <path>               write the contents of a file to standard output
+//	get <path> [local]       download a file, without overwriting local files
+//	put <local> <path>       upload a file
+//	rm <path>                delete a file, or a directory if path ends with /
+//
+// The get command downloads to a file with the .part suffix and renames it
+// once complete. With the -continue flag, it resumes from that file if a
+// previous download was interrupted; otherwise it starts over.
+//
+// The storage zone, keys and endpoint are read from flags, falling back to the
+// BUNNY_STORAGE_ZONE, BUNNY_WRITE_API_KEY, BUNNY_READ_API_KEY and
+// BUNNY_ENDPOINT environment variables.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	code := run(ctx, os.Args[1:], os.Getenv, os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(code)
+}
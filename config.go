@@ -3,6 +3,9 @@ package bunnystorage
 import (
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +20,10 @@ const (
 	// ErrInvalidEndpoint is returned when an endpoint is invalid.
 	ErrInvalidEndpoint xerrors.Error = "invalid endpoint"
 
+	// ErrInvalidBaseURL is returned when a Config is created with a base URL
+	// that is not an absolute HTTP or HTTPS URL.
+	ErrInvalidBaseURL xerrors.Error = "invalid base URL"
+
 	// ErrStorageZoneRequired is returned when a Config is created without a
 	// storage zone.
 	ErrStorageZoneRequired xerrors.Error = "storage zone required"
@@ -36,8 +43,11 @@ const (
 
 // Default values for the Config struct.
 const (
-	DefaultMaxRetries int           = 3
-	DefaultTimeout    time.Duration = 60 * time.Second
+	DefaultMaxRetries  int           = 3
+	DefaultConcurrency int           = 4
+	DefaultTimeout     time.Duration = 60 * time.Second
+
+	DefaultFailoverCooldown time.Duration = 30 * time.Second
 )
 
 const (
@@ -52,9 +62,35 @@ type Operation int
 // Config holds the basic configuration for the Bunny.net Storage API.
 type Config struct {
 	// Logger is the structured logger to use for logging information about API
-	// requests and responses.
+	// requests and responses. Every attempt of a request is logged with its
+	// operation, method, path, status, sizes, duration and attempt number.
+	// The response size is the Content-Length sent by the API, or zero when
+	// it is unknown, and the duration stops when the response headers arrive,
+	// so it does not include reading the body of downloads. The AccessKey
+	// header is always redacted.
+	//
+	// This field is optional.
 	Logger *slog.Logger
 
+	// LogLevel is the level at which successful requests are logged. It
+	// defaults to slog.LevelDebug.
+	//
+	// This field is optional.
+	LogLevel slog.Leveler
+
+	// ErrorLogLevel is the level at which requests failing with a network
+	// error or a non-2xx status code are logged. It defaults to
+	// slog.LevelWarn.
+	//
+	// This field is optional.
+	ErrorLogLevel slog.Leveler
+
+	// LogErrorBodies makes the client include the body of error responses in
+	// their log records when Logger is enabled for slog.LevelDebug.
+	//
+	// This field is optional.
+	LogErrorBodies bool
+
 	// StorageZone is the name of the storage zone to connect to.
 	StorageZone string
 
@@ -72,17 +108,119 @@ type Config struct {
 	// Endpoint is the endpoint to use for the API.
 	Endpoint Endpoint
 
-	// MaxRetries specifies the maximum number of times to retry a request if it
-	// fails due to rate limiting.
+	// BaseURL is the base URL of the API, such as the address of a storage
+	// region without a predefined Endpoint, a proxy, or a fake server used in
+	// tests. It must be an absolute HTTP or HTTPS URL and may include a path
+	// prefix. When set, it takes precedence over Endpoint.
+	//
+	// This field is optional.
+	BaseURL string
+
+	// Fallbacks is an ordered list of the regions the storage zone is
+	// replicated to. Read requests, such as listing, downloading and
+	// describing files, that fail on the primary region with a network error
+	// or a 5xx status code are sent to the next region in the list. Write
+	// requests are always sent to the primary region.
+	//
+	// This field is optional.
+	Fallbacks []Endpoint
+
+	// FallbackBaseURLs is like Fallbacks, for regions without a predefined
+	// Endpoint, proxies or fake servers used in tests. When set, it takes
+	// precedence over Fallbacks.
+	//
+	// This field is optional.
+	FallbackBaseURLs []string
+
+	// FailoverCooldown is the time a region failing a read request is skipped
+	// for, unless every region is failing. It defaults to
+	// DefaultFailoverCooldown.
+	//
+	// This field is optional.
+	FailoverCooldown time.Duration
+
+	// HedgePolicy enables hedged reads, sending a second copy of read
+	// requests that are slow to get a response from the primary region to the
+	// first fallback region. It requires Fallbacks or FallbackBaseURLs.
+	//
+	// This field is optional.
+	HedgePolicy *HedgePolicy
+
+	// CircuitBreaker enables circuit breakers, which stop sending requests to
+	// an endpoint failing consistently, for reads and writes separately, and
+	// fail them with ErrCircuitOpen instead. With Fallbacks, reads fail over
+	// to the next region while the breaker of a region is open.
+	//
+	// This field is optional.
+	CircuitBreaker *CircuitBreaker
+
+	// HTTPClient is the HTTP client used to make requests to the API. Its
+	// settings, such as its cookie jar and redirect policy, are preserved, but
+	// its transport is wrapped with Middleware and the retry logic of the
+	// client, and its timeout is replaced by Timeout.
+	//
+	// This field is optional.
+	HTTPClient *http.Client
+
+	// Transport is the base HTTP transport used to make requests to the API,
+	// such as one configured with a proxy, mTLS or connection pool limits.
+	// When set, it takes precedence over the transport of HTTPClient.
+	//
+	// This field is optional.
+	Transport http.RoundTripper
+
+	// Middleware is an ordered chain of middleware wrapping the transport. The
+	// first middleware is the outermost one. Retries wrap the whole chain, so
+	// every middleware sees each attempt of a request.
+	//
+	// This field is optional.
+	Middleware []Middleware
+
+	// ReadRateLimit limits the rate of read requests, such as listing and
+	// downloading files. The limit is shared by every goroutine using the
+	// client and applies to each attempt of a request.
+	//
+	// This field is optional.
+	ReadRateLimit RateLimit
+
+	// WriteRateLimit limits the rate of write requests, such as uploading and
+	// deleting files. The limit is shared by every goroutine using the client
+	// and applies to each attempt of a request.
+	//
+	// This field is optional.
+	WriteRateLimit RateLimit
+
+	// MaxRetries specifies the maximum number of times to retry a request
+	// failing with a network error or a status code accepted by
+	// RetryPolicy.RetryableStatus, which are 429, 500, 502, 503 and 504 by
+	// default. It defaults to DefaultMaxRetries.
 	//
 	// This field is optional.
 	MaxRetries int
 
+	// RetryPolicy controls which failed requests are retried and how long the
+	// client waits between attempts. It defaults to exponential backoff with
+	// full jitter, starting at one second and capped at thirty seconds,
+	// honoring Retry-After.
+	//
+	// This field is optional.
+	RetryPolicy *RetryPolicy
+
+	// Concurrency specifies the maximum number of concurrent requests made by
+	// operations that fan out over many objects, such as Walk.
+	//
+	// This field is optional.
+	Concurrency int
+
 	// Timeout is the time limit for requests made by the client to the  API.
 	//
 	// This field is optional.
 	Timeout time.Duration
 
+	// now returns the current time to the circuit breakers and the failover
+	// between regions. It defaults to time.Now and is only replaced by tests.
+	now func() time.Time
+
 	// mu protects Config initialization.
 	mu sync.Mutex
 }
@@ -100,6 +238,35 @@ func (c *Config) AccessKey(op Operation) string {
 	return ""
 }
 
+// baseURL returns the base URL of the API, without a trailing slash.
+func (c *Config) baseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+
+	return c.Endpoint.String()
+}
+
+// fallbackURLs returns the base URLs of the fallback regions, without a
+// trailing slash.
+func (c *Config) fallbackURLs() []string {
+	urls := make([]string, 0, len(c.FallbackBaseURLs)+len(c.Fallbacks))
+
+	if len(c.FallbackBaseURLs) > 0 {
+		for _, u := range c.FallbackBaseURLs {
+			urls = append(urls, strings.TrimSuffix(u, "/"))
+		}
+
+		return urls
+	}
+
+	for _, e := range c.Fallbacks {
+		urls = append(urls, e.String())
+	}
+
+	return urls
+}
+
 // init initializes missing Config fields with their default values.
 func (c *Config) init() {
 	c.mu.Lock()
@@ -113,9 +280,21 @@ func (c *Config) init() {
 		c.MaxRetries = DefaultMaxRetries
 	}
 
+	if c.Concurrency < 1 {
+		c.Concurrency = DefaultConcurrency
+	}
+
 	if c.Timeout < 1 {
 		c.Timeout = DefaultTimeout
 	}
+
+	if c.FailoverCooldown < 1 {
+		c.FailoverCooldown = DefaultFailoverCooldown
+	}
+
+	if c.now == nil {
+		c.now = time.Now
+	}
 }
 
 // validate returns an error if the config is invalid.
@@ -128,6 +307,22 @@ func (c *Config) validate() error {
 		return ErrStorageZoneKeyRequired
 	}
 
+	if err := c.RetryPolicy.validate(); err != nil {
+		return err
+	}
+
+	if err := c.validateFallbacks(); err != nil {
+		return err
+	}
+
+	if err := c.HedgePolicy.validate(c.fallbackURLs()); err != nil {
+		return err
+	}
+
+	if c.BaseURL != "" {
+		return validateBaseURL(c.BaseURL)
+	}
+
 	if c.Endpoint == 0 {
 		return ErrEndpointRequired
 	}
@@ -138,3 +333,48 @@ func (c *Config) validate() error {
 
 	return nil
 }
+
+// validateFallbacks returns an error if one of the fallback regions is
+// invalid.
+func (c *Config) validateFallbacks() error {
+	if len(c.FallbackBaseURLs) > 0 {
+		for _, u := range c.FallbackBaseURLs {
+			if err := validateBaseURL(u); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, e := range c.Fallbacks {
+		if !e.IsValid() {
+			return fmt.Errorf("%w: %d", ErrInvalidEndpoint, e)
+		}
+	}
+
+	return nil
+}
+
+// validateBaseURL returns an error if the given base URL is not an absolute
+// HTTP or HTTPS URL without a query or fragment.
+func validateBaseURL(s string) error {
+	uri, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBaseURL, err)
+	}
+
+	if uri.Scheme != "http" && uri.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrInvalidBaseURL, uri.Scheme)
+	}
+
+	if uri.Host == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidBaseURL)
+	}
+
+	if uri.RawQuery != "" || uri.Fragment != "" {
+		return fmt.Errorf("%w: query and fragment are not allowed", ErrInvalidBaseURL)
+	}
+
+	return nil
+}
@@ -52,6 +52,61 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "Valid base URL",
+			config: &Config{
+				StorageZone: "storage-zone",
+				Key:         "api-key",
+				BaseURL:     "https://storage.example.com/bunny/",
+			},
+			expectErr: false,
+		},
+		{
+			name: "Base URL takes precedence over invalid endpoint",
+			config: &Config{
+				StorageZone: "storage-zone",
+				Key:         "api-key",
+				Endpoint:    Endpoint(999),
+				BaseURL:     "http://127.0.0.1:8080",
+			},
+			expectErr: false,
+		},
+		{
+			name: "Base URL without scheme",
+			config: &Config{
+				StorageZone: "storage-zone",
+				Key:         "api-key",
+				BaseURL:     "storage.example.com",
+			},
+			expectErr: true,
+		},
+		{
+			name: "Base URL with unsupported scheme",
+			config: &Config{
+				StorageZone: "storage-zone",
+				Key:         "api-key",
+				BaseURL:     "ftp://storage.example.com",
+			},
+			expectErr: true,
+		},
+		{
+			name: "Base URL without host",
+			config: &Config{
+				StorageZone: "storage-zone",
+				Key:         "api-key",
+				BaseURL:     "https://",
+			},
+			expectErr: true,
+		},
+		{
+			name: "Base URL with query",
+			config: &Config{
+				StorageZone: "storage-zone",
+				Key:         "api-key",
+				BaseURL:     "https://storage.example.com/?key=value",
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -69,3 +124,41 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_BaseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config *Config
+		want   string
+	}{
+		{
+			name: "Endpoint",
+			config: &Config{
+				Endpoint: EndpointNewYork,
+			},
+			want: "https://ny.storage.bunnycdn.com",
+		},
+		{
+			name: "Base URL",
+			config: &Config{
+				Endpoint: EndpointNewYork,
+				BaseURL:  "https://storage.example.com/bunny/",
+			},
+			want: "https://storage.example.com/bunny",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.config.baseURL(); got != tt.want {
+				t.Errorf("baseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package bunnystorage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrOverlappingPaths is returned when the source and destination of a copy or
+// move are the same, or when a directory is copied into itself.
+const ErrOverlappingPaths xerrors.Error = "source and destination overlap"
+
+// CopyOption configures a call to Client.Copy, Client.Move,
+// Client.CopyDirectory or Client.MoveDirectory.
+type CopyOption func(*copyOptions)
+
+// copyOptions holds the options of a copy or move.
+type copyOptions struct {
+	dst *Client
+}
+
+// WithDestinationClient makes a copy or move write to the storage zone of dst
+// instead of the storage zone of the Client it is called on, which allows
+// copying between storage zones.
+func WithDestinationClient(dst *Client) CopyOption {
+	return func(o *copyOptions) {
+		o.dst = dst
+	}
+}
+
+// CopyResult summarizes a call to Client.CopyDirectory or Client.MoveDirectory.
+type CopyResult struct {
+	// Copied lists the files that were copied, and deleted from the source
+	// when moving, as slash-separated paths relative to the source and
+	// destination directories, sorted in lexical order.
+	Copied []string
+}
+
+// Copy copies the file at src to dst, both given as slash-separated paths
+// relative to the root of the storage zone.
+//
+// Since the API has no server-side copy, the file is streamed from a download
+// into an upload without being buffered in memory. The checksum reported for
+// the source is sent with the upload so the API rejects corrupted data, and
+// the destination is verified against the checksum of the streamed bytes once
+// uploaded. If verification fails, the returned error matches
+// ErrChecksumMismatch.
+//
+// Because the streamed data cannot be sent again, a failed upload is not
+// retried, even if the error would be retried for other requests; the caller
+// can call Copy again.
+func (c *Client) Copy(ctx context.Context, src, dst string, opts ...CopyOption) error {
+	o := c.copyOptions(opts)
+
+	src, dst = cleanPath(src), cleanPath(dst)
+
+	if c.sameZone(o.dst) && src == dst {
+		return fmt.Errorf("%w: %s", ErrOverlappingPaths, src)
+	}
+
+	return c.copyFile(ctx, o.dst, src, dst)
+}
+
+// Move moves the file at src to dst, both given as slash-separated paths
+// relative to the root of the storage zone. It works like Copy, and only
+// deletes the source once the destination has been verified.
+func (c *Client) Move(ctx context.Context, src, dst string, opts ...CopyOption) error {
+	if err := c.Copy(ctx, src, dst, opts...); err != nil {
+		return err
+	}
+
+	src = cleanPath(src)
+
+	if _, err := c.Delete(ctx, apiPath(path.Dir(src)), path.Base(src)); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// CopyDirectory copies every file in the tree rooted at src to the same
+// relative path under dst, using up to Config.Concurrency concurrent copies.
+// Each file is copied and verified as with Copy.
+//
+// If some copies fail, CopyDirectory carries on with the others and returns
+// the errors joined together, along with a result listing the files that were
+// copied.
+func (c *Client) CopyDirectory(ctx context.Context, src, dst string, opts ...CopyOption) (*CopyResult, error) {
+	return c.copyTree(ctx, src, dst, c.copyOptions(opts), false)
+}
+
+// MoveDirectory moves every file in the tree rooted at src to the same
+// relative path under dst. It works like CopyDirectory, deleting every source
+// file once its copy has been verified, and then the source directory if no
+// file is left in it.
+func (c *Client) MoveDirectory(ctx context.Context, src, dst string, opts ...CopyOption) (*CopyResult, error) {
+	return c.copyTree(ctx, src, dst, c.copyOptions(opts), true)
+}
+
+// copyOptions returns the copy options resulting from applying opts.
+func (c *Client) copyOptions(opts []CopyOption) *copyOptions {
+	o := &copyOptions{
+		dst: c,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.dst == nil {
+		o.dst = c
+	}
+
+	return o
+}
+
+// sameZone reports whether c and other access the same storage zone.
+func (c *Client) sameZone(other *Client) bool {
+	return c == other ||
+		(c.cfg.baseURL() == other.cfg.baseURL() && c.cfg.StorageZone == other.cfg.StorageZone)
+}
+
+// copyFile streams the file at src to dst in the storage zone of the
+// destination client, and verifies the result.
+func (c *Client) copyFile(ctx context.Context, dstClient *Client, src, dst string) error {
+	srcDir, srcName := apiPath(path.Dir(src)), path.Base(src)
+	dstDir, dstName := apiPath(path.Dir(dst)), path.Base(dst)
+
+	obj, err := c.lookup(ctx, srcDir, srcName)
+	if err != nil {
+		return err
+	}
+
+	var opts []DownloadOption
+	if obj.Checksum != "" {
+		opts = append(opts, WithExpectedChecksum(obj.Checksum))
+	}
+
+	body, _, err := c.DownloadStream(ctx, srcDir, srcName, opts...)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer body.Close()
+
+	hasher := sha256.New()
+
+	_, err = dstClient.upload(ctx, dstDir, dstName, obj.Checksum, io.TeeReader(body, hasher), obj.Length)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	uploaded, err := dstClient.lookup(ctx, dstDir, dstName)
+	if err != nil {
+		return err
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+
+	if uploaded.Checksum != "" {
+		if err = compareChecksum(uploaded.Checksum, sum); err != nil {
+			return fmt.Errorf("%s: %w", dst, err)
+		}
+	}
+
+	return nil
+}
+
+// copyTree copies, or moves, every file in the tree rooted at src to dst.
+func (c *Client) copyTree(ctx context.Context, src, dst string, o *copyOptions, move bool) (*CopyResult, error) {
+	src, dst = cleanPath(src), cleanPath(dst)
+
+	if c.sameZone(o.dst) && (src == dst || src == "." || strings.HasPrefix(dst, src+"/")) {
+		return nil, fmt.Errorf("%w: %s and %s", ErrOverlappingPaths, src, dst)
+	}
+
+	files, err := c.treeFiles(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result = &CopyResult{}
+		errs   []error
+		mu     sync.Mutex
+	)
+
+	parallel(ctx, c.cfg.Concurrency, len(files), func(ctx context.Context, i int) {
+		name := files[i]
+
+		copyErr := c.copyFile(ctx, o.dst, path.Join(src, name), path.Join(dst, name))
+		if copyErr == nil && move {
+			srcFile := path.Join(src, name)
+			_, copyErr = c.Delete(ctx, apiPath(path.Dir(srcFile)), path.Base(srcFile))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if copyErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, copyErr))
+
+			return
+		}
+
+		result.Copied = append(result.Copied, name)
+	})
+
+	if err = ctx.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("%w", err))
+	}
+
+	if move && len(errs) == 0 && src != "." {
+		if err = c.deleteEmptyTree(ctx, src); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	sort.Strings(result.Copied)
+
+	return result, errors.Join(errs...)
+}
+
+// treeFiles returns the paths of every file in the tree rooted at root,
+// relative to root.
+func (c *Client) treeFiles(ctx context.Context, root string) ([]string, error) {
+	var files []string
+
+	err := c.Walk(ctx, root, func(name string, obj *Object, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !obj.IsDirectory {
+			rel := name
+			if root != "." {
+				rel = strings.TrimPrefix(name, root+"/")
+			}
+
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// deleteEmptyTree deletes the directory at root if no file is left in it.
+func (c *Client) deleteEmptyTree(ctx context.Context, root string) error {
+	files, err := c.treeFiles(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	if len(files) > 0 {
+		return nil
+	}
+
+	if _, err = c.deleteDirectory(ctx, root); err != nil {
+		return fmt.Errorf("%s: %w", root, err)
+	}
+
+	return nil
+}
+
+// cleanPath returns the slash-separated path relative to the root of the
+// storage zone for p, which may have leading or trailing slashes.
+func cleanPath(p string) string {
+	return path.Clean(strings.Trim(p, "/"))
+}
@@ -0,0 +1,292 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+func TestClient_Copy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		move      bool
+		corrupt   bool
+		src       string
+		dst       string
+		wantFiles []string
+		wantErr   error
+	}{
+		{
+			name:      "copy",
+			src:       "/docs/a.txt",
+			dst:       "/backup/a.txt",
+			wantFiles: []string{"backup/a.txt", "docs/a.txt"},
+		},
+		{
+			name:      "move",
+			move:      true,
+			src:       "docs/a.txt",
+			dst:       "a.txt",
+			wantFiles: []string{"a.txt"},
+		},
+		{
+			name:      "same path",
+			src:       "/docs/a.txt",
+			dst:       "docs/a.txt",
+			wantFiles: []string{"docs/a.txt"},
+			wantErr:   bunnystorage.ErrOverlappingPaths,
+		},
+		{
+			name:      "missing source",
+			src:       "/docs/missing.txt",
+			dst:       "/backup/missing.txt",
+			wantFiles: []string{"docs/a.txt"},
+			wantErr:   bunnystorage.ErrNotFound,
+		},
+		{
+			name:      "corrupted move",
+			move:      true,
+			corrupt:   true,
+			src:       "/docs/a.txt",
+			dst:       "/backup/a.txt",
+			wantFiles: []string{"docs/a.txt"},
+			wantErr:   bunnystorage.ErrChecksumMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.PutFile("docs/a.txt", []byte("alpha"))
+
+			cfg := srv.Config()
+			if tt.corrupt {
+				cfg.Middleware = []bunnystorage.Middleware{corruptDownloads()}
+			}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			op := client.Copy
+			if tt.move {
+				op = client.Move
+			}
+
+			if err = op(context.Background(), tt.src, tt.dst); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := srv.Files(); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", got, tt.wantFiles)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got, _ := srv.File(tt.dst); string(got) != "alpha" {
+				t.Errorf("destination = %q, want %q", got, "alpha")
+			}
+		})
+	}
+}
+
+func TestClient_Copy_ContentLength(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{
+			name:    "file",
+			content: []byte("alpha"),
+		},
+		{
+			name:    "empty file",
+			content: []byte{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.PutFile("docs/a.txt", tt.content)
+
+			var (
+				mu      sync.Mutex
+				lengths []int64
+				chunked bool
+			)
+
+			probe := func(next http.RoundTripper) http.RoundTripper {
+				return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					if req.Method == http.MethodPut {
+						mu.Lock()
+						lengths = append(lengths, req.ContentLength)
+						chunked = chunked || (req.ContentLength == 0 && req.Body != nil && req.Body != http.NoBody)
+						mu.Unlock()
+					}
+
+					return next.RoundTrip(req)
+				})
+			}
+
+			cfg := srv.Config()
+			cfg.Middleware = []bunnystorage.Middleware{probe}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			if err = client.Copy(context.Background(), "docs/a.txt", "backup/a.txt"); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+
+			if want := []int64{int64(len(tt.content))}; !reflect.DeepEqual(lengths, want) || chunked {
+				t.Errorf("Copy() sent Content-Length %v (chunked %t), want %v", lengths, chunked, want)
+			}
+
+			if got, ok := srv.File("backup/a.txt"); !ok || string(got) != string(tt.content) {
+				t.Errorf("Copy() wrote %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestClient_CopyDirectory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		move       bool
+		src        string
+		dst        string
+		wantCopied []string
+		wantFiles  []string
+		wantErr    error
+	}{
+		{
+			name:       "copy",
+			src:        "/docs/",
+			dst:        "/backup/",
+			wantCopied: []string{"a.txt", "sub/b.txt"},
+			wantFiles:  []string{"backup/a.txt", "backup/sub/b.txt", "docs/a.txt", "docs/sub/b.txt", "top.txt"},
+		},
+		{
+			name:       "move",
+			move:       true,
+			src:        "docs",
+			dst:        "archive/docs",
+			wantCopied: []string{"a.txt", "sub/b.txt"},
+			wantFiles:  []string{"archive/docs/a.txt", "archive/docs/sub/b.txt", "top.txt"},
+		},
+		{
+			name:      "into itself",
+			src:       "/docs/",
+			dst:       "/docs/sub/",
+			wantFiles: []string{"docs/a.txt", "docs/sub/b.txt", "top.txt"},
+			wantErr:   bunnystorage.ErrOverlappingPaths,
+		},
+		{
+			name:      "root",
+			src:       "/",
+			dst:       "/backup/",
+			wantFiles: []string{"docs/a.txt", "docs/sub/b.txt", "top.txt"},
+			wantErr:   bunnystorage.ErrOverlappingPaths,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, srv := bunnystoragetest.NewClient(t)
+
+			srv.PutFile("docs/a.txt", []byte("alpha"))
+			srv.PutFile("docs/sub/b.txt", []byte("bravo"))
+			srv.PutFile("top.txt", []byte("top"))
+
+			op := client.CopyDirectory
+			if tt.move {
+				op = client.MoveDirectory
+			}
+
+			result, err := op(context.Background(), tt.src, tt.dst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			var copied []string
+			if result != nil {
+				copied = result.Copied
+			}
+
+			if !reflect.DeepEqual(copied, tt.wantCopied) {
+				t.Errorf("copied = %q, want %q", copied, tt.wantCopied)
+			}
+
+			if got := srv.Files(); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestClient_Copy_CrossZone(t *testing.T) {
+	t.Parallel()
+
+	src, srcSrv := bunnystoragetest.NewClient(t)
+	dst, dstSrv := bunnystoragetest.NewClient(t)
+
+	srcSrv.PutFile("docs/a.txt", []byte("alpha"))
+	srcSrv.PutFile("docs/sub/b.txt", []byte("bravo"))
+
+	ctx := context.Background()
+
+	if err := src.Move(ctx, "/docs/a.txt", "/docs/a.txt", bunnystorage.WithDestinationClient(dst)); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+
+	result, err := src.CopyDirectory(ctx, "/", "/", bunnystorage.WithDestinationClient(dst))
+	if err != nil {
+		t.Fatalf("CopyDirectory() error = %v", err)
+	}
+
+	if want := []string{"docs/sub/b.txt"}; !reflect.DeepEqual(result.Copied, want) {
+		t.Errorf("CopyDirectory() copied = %q, want %q", result.Copied, want)
+	}
+
+	if got, want := srcSrv.Files(), []string{"docs/sub/b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("source files = %q, want %q", got, want)
+	}
+
+	if got, want := dstSrv.Files(), []string{"docs/a.txt", "docs/sub/b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("destination files = %q, want %q", got, want)
+	}
+}
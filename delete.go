@@ -0,0 +1,212 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"git.sr.ht/~jamesponddotco/xstd-go/xstrings"
+)
+
+// ErrDeleteRoot is returned when DeleteDirectory is asked to delete the root
+// of the storage zone without WithForce.
+const ErrDeleteRoot xerrors.Error = "refusing to delete the root of the storage zone"
+
+// DeleteOption configures a call to Client.DeleteDirectory.
+type DeleteOption func(*deleteOptions)
+
+// deleteOptions holds the options of a call to Client.DeleteDirectory.
+type deleteOptions struct {
+	force     bool
+	recursive bool
+}
+
+// WithForce allows DeleteDirectory to delete the root of the storage zone,
+// which removes every file in it.
+func WithForce() DeleteOption {
+	return func(o *deleteOptions) {
+		o.force = true
+	}
+}
+
+// WithRecursive makes DeleteDirectory walk the directory and delete every file
+// and subdirectory in it one by one, reporting each of them, instead of
+// relying on the API to delete the whole tree in a single request.
+func WithRecursive() DeleteOption {
+	return func(o *deleteOptions) {
+		o.recursive = true
+	}
+}
+
+// DeleteResult lists the objects removed by Client.DeleteDirectory, as
+// slash-separated paths relative to the root of the storage zone, sorted in
+// lexical order. Directories have a trailing slash, and the root of the
+// storage zone is reported as "/".
+type DeleteResult struct {
+	// Deleted lists the objects that were deleted. Without WithRecursive, it
+	// only holds the directory itself, since the API does not report its
+	// contents.
+	Deleted []string
+}
+
+// DeleteDirectory deletes a directory and everything in it from the storage
+// zone. It refuses to delete the root of the storage zone unless WithForce is
+// given, in which case the root itself is kept but emptied.
+//
+// With WithRecursive, files are deleted using up to Config.Concurrency
+// concurrent requests, then directories are deleted deepest first. If some
+// deletions fail, DeleteDirectory carries on with the others, keeps the
+// directories containing objects that could not be deleted, and returns the
+// errors joined together along with a result listing what was deleted.
+func (c *Client) DeleteDirectory(ctx context.Context, dir string, opts ...DeleteOption) (*DeleteResult, error) {
+	o := &deleteOptions{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dir = cleanPath(dir)
+
+	if dir == "." && !o.force {
+		return nil, ErrDeleteRoot
+	}
+
+	if o.recursive {
+		return c.deleteTree(ctx, dir)
+	}
+
+	if _, err := c.deleteDirectory(ctx, dir); err != nil {
+		return nil, err
+	}
+
+	return &DeleteResult{
+		Deleted: []string{directoryName(dir)},
+	}, nil
+}
+
+// deleteDirectory deletes the directory at the given path, relative to the
+// root of the storage zone, using the trailing-slash form of the API.
+func (c *Client) deleteDirectory(ctx context.Context, dir string) (*Response, error) {
+	uri := xstrings.JoinWithSeparator("/", c.cfg.baseURL(), c.cfg.StorageZone, directoryName(dir))
+
+	headers := map[string]string{
+		"AccessKey": c.cfg.AccessKey(OperationWrite),
+	}
+
+	req, err := c.request(ctx, http.MethodDelete, uri, headers, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return resp, fmt.Errorf("%w", err)
+	}
+
+	return resp, nil
+}
+
+// deleteTree walks the directory at the given path and deletes its files,
+// then its directories, one by one.
+func (c *Client) deleteTree(ctx context.Context, root string) (*DeleteResult, error) {
+	var files, dirs []string
+
+	err := c.Walk(ctx, root, func(name string, obj *Object, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if obj.IsDirectory {
+			dirs = append(dirs, name)
+		} else {
+			files = append(files, name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result = &DeleteResult{}
+		failed []string
+		errs   []error
+		mu     sync.Mutex
+	)
+
+	parallel(ctx, c.cfg.Concurrency, len(files), func(ctx context.Context, i int) {
+		name := files[i]
+		_, deleteErr := c.Delete(ctx, apiPath(path.Dir(name)), path.Base(name))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if deleteErr != nil {
+			failed = append(failed, name)
+			errs = append(errs, fmt.Errorf("%s: %w", name, deleteErr))
+
+			return
+		}
+
+		result.Deleted = append(result.Deleted, name)
+	})
+
+	// Delete the deepest directories first, so every directory is empty by
+	// the time it is deleted.
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+
+	for _, dir := range dirs {
+		if err = ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("%w", err))
+
+			break
+		}
+
+		if dir == "." || containsAny(dir, failed) {
+			continue
+		}
+
+		if _, err = c.deleteDirectory(ctx, dir); err != nil {
+			failed = append(failed, dir)
+			errs = append(errs, fmt.Errorf("%s: %w", dir, err))
+
+			continue
+		}
+
+		result.Deleted = append(result.Deleted, directoryName(dir))
+	}
+
+	sort.Strings(result.Deleted)
+
+	return result, errors.Join(errs...)
+}
+
+// containsAny reports whether any of the given paths is inside dir.
+func containsAny(dir string, paths []string) bool {
+	for _, p := range paths {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// directoryName returns the name reported for the directory at the given path
+// in a DeleteResult.
+func directoryName(dir string) string {
+	if dir == "." {
+		return "/"
+	}
+
+	return dir + "/"
+}
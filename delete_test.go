@@ -0,0 +1,179 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+func TestClient_DeleteDirectory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		dir         string
+		opts        []bunnystorage.DeleteOption
+		failDelete  string
+		wantDeleted []string
+		wantFiles   []string
+		wantErr     error
+	}{
+		{
+			name:        "directory",
+			dir:         "/docs/",
+			wantDeleted: []string{"docs/"},
+			wantFiles:   []string{"top.txt"},
+		},
+		{
+			name:        "directory without slashes",
+			dir:         "docs",
+			wantDeleted: []string{"docs/"},
+			wantFiles:   []string{"top.txt"},
+		},
+		{
+			name:    "root",
+			dir:     "/",
+			wantErr: bunnystorage.ErrDeleteRoot,
+			wantFiles: []string{
+				"docs/a.txt",
+				"docs/sub/b.txt",
+				"docs/sub/c.txt",
+				"top.txt",
+			},
+		},
+		{
+			name:    "root as dot",
+			dir:     ".",
+			wantErr: bunnystorage.ErrDeleteRoot,
+			wantFiles: []string{
+				"docs/a.txt",
+				"docs/sub/b.txt",
+				"docs/sub/c.txt",
+				"top.txt",
+			},
+		},
+		{
+			name:        "forced root",
+			dir:         "/",
+			opts:        []bunnystorage.DeleteOption{bunnystorage.WithForce()},
+			wantDeleted: []string{"/"},
+			wantFiles:   []string{},
+		},
+		{
+			name:    "missing",
+			dir:     "/missing/",
+			wantErr: bunnystorage.ErrNotFound,
+			wantFiles: []string{
+				"docs/a.txt",
+				"docs/sub/b.txt",
+				"docs/sub/c.txt",
+				"top.txt",
+			},
+		},
+		{
+			name: "recursive",
+			dir:  "/docs/",
+			opts: []bunnystorage.DeleteOption{bunnystorage.WithRecursive()},
+			wantDeleted: []string{
+				"docs/",
+				"docs/a.txt",
+				"docs/sub/",
+				"docs/sub/b.txt",
+				"docs/sub/c.txt",
+			},
+			wantFiles: []string{"top.txt"},
+		},
+		{
+			name: "recursive forced root",
+			dir:  "/",
+			opts: []bunnystorage.DeleteOption{bunnystorage.WithRecursive(), bunnystorage.WithForce()},
+			wantDeleted: []string{
+				"docs/",
+				"docs/a.txt",
+				"docs/sub/",
+				"docs/sub/b.txt",
+				"docs/sub/c.txt",
+				"top.txt",
+			},
+			wantFiles: []string{},
+		},
+		{
+			name:       "recursive with failure",
+			dir:        "/docs/",
+			opts:       []bunnystorage.DeleteOption{bunnystorage.WithRecursive()},
+			failDelete: "docs/sub/b.txt",
+			wantDeleted: []string{
+				"docs/a.txt",
+				"docs/sub/c.txt",
+			},
+			wantFiles: []string{"docs/sub/b.txt", "top.txt"},
+			wantErr:   bunnystorage.ErrBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.PutFile("docs/a.txt", []byte("a"))
+			srv.PutFile("docs/sub/b.txt", []byte("b"))
+			srv.PutFile("docs/sub/c.txt", []byte("c"))
+			srv.PutFile("top.txt", []byte("top"))
+
+			cfg := srv.Config()
+
+			if tt.failDelete != "" {
+				cfg.Middleware = []bunnystorage.Middleware{
+					func(next http.RoundTripper) http.RoundTripper {
+						return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+							if req.Method == http.MethodDelete && strings.HasSuffix(req.URL.Path, "/"+tt.failDelete) {
+								return &http.Response{
+									StatusCode: http.StatusBadRequest,
+									Header:     http.Header{},
+									Body:       http.NoBody,
+									Request:    req,
+								}, nil
+							}
+
+							return next.RoundTrip(req)
+						})
+					},
+				}
+			}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			result, err := client.DeleteDirectory(context.Background(), tt.dir, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteDirectory() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var deleted []string
+			if result != nil {
+				deleted = result.Deleted
+			}
+
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("DeleteDirectory() deleted = %q, want %q", deleted, tt.wantDeleted)
+			}
+
+			if got := srv.Files(); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", got, tt.wantFiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PartialFileSuffix is appended to the local file name by DownloadFile to name
+// the file holding an incomplete download.
+const PartialFileSuffix string = ".part"
+
+// WithNoOverwrite makes DownloadFile fail with an error matching os.ErrExist
+// instead of replacing the local file if it already exists.
+//
+// The check is repeated when the download completes by hard linking the
+// partial file to the local file name, which fails if the name was taken in
+// the meantime, so a file created during the download is never replaced. In
+// that case the complete partial file is kept. The local file system must
+// support hard links.
+func WithNoOverwrite() DownloadOption {
+	return func(o *downloadOptions) {
+		o.noOverwrite = true
+	}
+}
+
+// DownloadFile downloads a file from the storage zone to the local file name,
+// and returns the metadata of the remote file.
+//
+// The file is first written to name with PartialFileSuffix appended. If that
+// file already exists, DownloadFile assumes it holds the start of the remote
+// file from a previous interrupted call and only requests the remaining bytes.
+// If the transfer fails midway, it is retried up to Config.MaxRetries times,
+// each time continuing from the last byte written, and the partial file is
+// kept on failure so a later call can resume it.
+//
+// Once complete, the partial file is verified against the checksum of the
+// remote file and renamed to name, replacing any existing file unless
+// WithNoOverwrite is given. If the
+// checksums differ, the download starts over from the first byte once; if it
+// fails again, the partial file is removed and the returned error matches
+// ErrChecksumMismatch. The file at name is never modified unless the download
+// succeeds.
+//
+// Only WithDownloadProgress and WithNoOverwrite are honored among opts, and
+// WithDownloadProgress reports the progress of every request separately.
+func (c *Client) DownloadFile(ctx context.Context, path, filename, name string, opts ...DownloadOption) (*Object, error) {
+	o := newDownloadOptions(opts)
+	path = "/" + strings.Trim(path, "/")
+	filename = filepath.Base(filename)
+
+	if o.noOverwrite {
+		if _, err := os.Lstat(name); err == nil {
+			return nil, fmt.Errorf("%s: %w", name, os.ErrExist)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w", err)
+		}
+	}
+
+	obj, err := c.lookup(ctx, path, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &resumableDownload{
+		client:   c,
+		name:     name + PartialFileSuffix,
+		path:     path,
+		filename: filename,
+		size:     obj.Length,
+		progress: o.progress,
+	}
+
+	err = d.download(ctx, obj.Checksum)
+	if errors.Is(err, ErrChecksumMismatch) {
+		// The partial file was truncated, so this starts over from the
+		// first byte.
+		err = d.download(ctx, obj.Checksum)
+		if errors.Is(err, ErrChecksumMismatch) {
+			if removeErr := os.Remove(d.name); removeErr != nil {
+				err = errors.Join(err, removeErr)
+			}
+		}
+	}
+
+	if err != nil {
+		return obj, err
+	}
+
+	if err = d.finish(name, o.noOverwrite); err != nil {
+		return obj, err
+	}
+
+	return obj, nil
+}
+
+// resumableDownload holds the state of a single call to DownloadFile.
+type resumableDownload struct {
+	client   *Client
+	file     *os.File
+	progress ProgressFunc
+	name     string
+	path     string
+	filename string
+	size     int64
+}
+
+// download opens the partial file, writes the missing bytes of the remote file
+// to it and verifies it against checksum.
+func (d *resumableDownload) download(ctx context.Context, checksum string) error {
+	file, err := os.OpenFile(d.name, os.O_RDWR|os.O_CREATE, 0o666) //nolint:gosec // same permissions as os.Create.
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	d.file = file
+
+	err = d.run(ctx)
+	if err == nil {
+		err = d.verify(checksum)
+	}
+
+	if closeErr := file.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("%w", closeErr)
+	}
+
+	return err
+}
+
+// finish moves the complete partial file to name. If noOverwrite is set, it
+// links the partial file to name instead of renaming it, since unlike a rename
+// a link fails if name exists.
+func (d *resumableDownload) finish(name string, noOverwrite bool) error {
+	if !noOverwrite {
+		if err := os.Rename(d.name, name); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	}
+
+	if err := os.Link(d.name, name); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if err := os.Remove(d.name); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// run writes the missing bytes of the remote file to the partial file.
+func (d *resumableDownload) run(ctx context.Context) error {
+	offset, err := d.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if offset > d.size {
+		if err = d.file.Truncate(0); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		offset = 0
+	}
+
+	policy := d.client.cfg.RetryPolicy.withDefaults()
+
+	for retries := 0; offset < d.size; {
+		n, fetchErr := d.fetch(ctx, offset)
+		offset += n
+
+		if fetchErr == nil && offset >= d.size {
+			return nil
+		}
+
+		var readErr *bodyReadError
+		if fetchErr != nil && !errors.As(fetchErr, &readErr) {
+			return fetchErr
+		}
+
+		if n > 0 {
+			retries = 0
+		}
+
+		if retries >= d.client.cfg.MaxRetries || ctx.Err() != nil {
+			if fetchErr == nil {
+				fetchErr = io.ErrUnexpectedEOF
+			}
+
+			return fmt.Errorf("%w", fetchErr)
+		}
+
+		delay, _ := policy.delay(retries, nil)
+
+		if err = sleep(ctx, delay); err != nil {
+			return err
+		}
+
+		retries++
+	}
+
+	return nil
+}
+
+// fetch requests the remote file from offset onwards and writes it to the
+// partial file at the same offset, returning the number of bytes written.
+// Errors reading the response body are returned as a *bodyReadError.
+func (d *resumableDownload) fetch(ctx context.Context, offset int64) (int64, error) {
+	opts := []DownloadOption{WithRange(offset, -1)}
+	if d.progress != nil {
+		opts = append(opts, WithDownloadProgress(d.progress))
+	}
+
+	body, _, err := d.client.DownloadStream(ctx, d.path, d.filename, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	if _, err = d.file.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+
+	src := &errorRecordingReader{r: io.LimitReader(body, d.size-offset)}
+
+	n, err := io.Copy(d.file, src)
+	if src.err != nil {
+		return n, &bodyReadError{err: src.err}
+	}
+
+	if err != nil {
+		return n, fmt.Errorf("%w", err)
+	}
+
+	return n, nil
+}
+
+// verify returns an error matching ErrChecksumMismatch if the partial file
+// does not match checksum, truncating it. Verification is skipped if checksum
+// is empty.
+func (d *resumableDownload) verify(checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	if _, err := d.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	got, err := ComputeSHA256(d.file)
+	if err != nil {
+		return err
+	}
+
+	if err = compareChecksum(got, checksum); err != nil {
+		if truncErr := d.file.Truncate(0); truncErr != nil {
+			return errors.Join(err, truncErr)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// bodyReadError is returned by resumableDownload.fetch when reading the
+// response body fails, which makes the transfer worth resuming.
+type bodyReadError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *bodyReadError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *bodyReadError) Unwrap() error {
+	return e.err
+}
+
+// errorRecordingReader is an io.Reader that records the first error other than
+// io.EOF returned by the underlying reader.
+type errorRecordingReader struct {
+	r   io.Reader
+	err error
+}
+
+// Read implements io.Reader.
+func (r *errorRecordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && r.err == nil {
+		r.err = err
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is.
+}
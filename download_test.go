@@ -0,0 +1,405 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+// rangeRecorder is a middleware recording the Range header of every file
+// download, and optionally cutting the first response body short.
+type rangeRecorder struct {
+	ranges []string
+	cutAt  int64
+	mu     sync.Mutex
+}
+
+func (r *rangeRecorder) middleware(next http.RoundTripper) http.RoundTripper {
+	return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || strings.HasSuffix(req.URL.Path, "/") {
+			return next.RoundTrip(req)
+		}
+
+		r.mu.Lock()
+		r.ranges = append(r.ranges, req.Header.Get("Range"))
+		first := len(r.ranges) == 1
+		r.mu.Unlock()
+
+		resp, err := next.RoundTrip(req)
+		if err != nil || !first || r.cutAt == 0 {
+			return resp, err
+		}
+
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(io.LimitReader(resp.Body, r.cutAt), failingReader{}),
+			Closer: resp.Body,
+		}
+
+		return resp, nil
+	})
+}
+
+func (r *rangeRecorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.ranges...)
+}
+
+// failingReader is an io.Reader that always fails, like a dropped connection.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestClient_DownloadFile(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 625)
+	existing := []byte("my local notes")
+
+	tests := []struct {
+		name       string
+		existing   []byte
+		partial    []byte
+		cutAt      int64
+		corrupt    bool
+		wantRanges []string
+		wantErr    error
+	}{
+		{
+			name:       "new file",
+			wantRanges: []string{"bytes=0-"},
+		},
+		{
+			name:       "existing file",
+			existing:   existing,
+			wantRanges: []string{"bytes=0-"},
+		},
+		{
+			name:       "partial file",
+			existing:   existing,
+			partial:    content[:4000],
+			wantRanges: []string{"bytes=4000-"},
+		},
+		{
+			name:       "complete partial file",
+			partial:    content,
+			wantRanges: nil,
+		},
+		{
+			name:       "larger partial file",
+			partial:    append(bytes.Clone(content), "extra"...),
+			wantRanges: []string{"bytes=0-"},
+		},
+		{
+			name:       "interrupted transfer",
+			partial:    content[:1000],
+			cutAt:      2500,
+			wantRanges: []string{"bytes=1000-", "bytes=3500-"},
+		},
+		{
+			name:       "corrupted partial file",
+			existing:   existing,
+			partial:    bytes.Repeat([]byte("x"), 4000),
+			wantRanges: []string{"bytes=4000-", "bytes=0-"},
+		},
+		{
+			name:       "corrupted download",
+			existing:   existing,
+			corrupt:    true,
+			wantRanges: []string{"bytes=0-", "bytes=0-"},
+			wantErr:    bunnystorage.ErrChecksumMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.PutFile("dir/file.bin", content)
+
+			rec := &rangeRecorder{cutAt: tt.cutAt}
+
+			cfg := srv.Config()
+			cfg.MaxRetries = 1
+			cfg.Middleware = []bunnystorage.Middleware{rec.middleware}
+
+			if tt.corrupt {
+				cfg.Middleware = append(cfg.Middleware, corruptDownloads())
+			}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			name := filepath.Join(t.TempDir(), "file.bin")
+			part := name + bunnystorage.PartialFileSuffix
+
+			if tt.existing != nil {
+				if err = os.WriteFile(name, tt.existing, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.partial != nil {
+				if err = os.WriteFile(part, tt.partial, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			obj, err := client.DownloadFile(context.Background(), "/dir", "file.bin", name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DownloadFile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := rec.recorded(); !reflect.DeepEqual(got, tt.wantRanges) {
+				t.Errorf("DownloadFile() ranges = %q, want %q", got, tt.wantRanges)
+			}
+
+			if _, err = os.Stat(part); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("DownloadFile() left the partial file, stat error = %v", err)
+			}
+
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantErr != nil {
+				if !bytes.Equal(got, tt.existing) {
+					t.Errorf("DownloadFile() changed the existing file to %d bytes", len(got))
+				}
+
+				return
+			}
+
+			if !bytes.Equal(got, content) {
+				t.Errorf("DownloadFile() wrote %d bytes, want %d", len(got), len(content))
+			}
+
+			if obj.Length != int64(len(content)) {
+				t.Errorf("DownloadFile() Length = %d, want %d", obj.Length, len(content))
+			}
+		})
+	}
+}
+
+func TestClient_DownloadFile_KeepsPartialFile(t *testing.T) {
+	t.Parallel()
+
+	srv := bunnystoragetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	srv.PutFile("file.bin", content)
+
+	cfg := srv.Config()
+	cfg.Middleware = []bunnystorage.Middleware{failingDownloads(2500)}
+	cfg.RetryPolicy = &bunnystorage.RetryPolicy{
+		MinDelay: time.Millisecond,
+		MaxDelay: time.Millisecond,
+	}
+
+	client, err := bunnystorage.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	name := filepath.Join(t.TempDir(), "file.bin")
+
+	if _, err = client.DownloadFile(context.Background(), "/", "file.bin", name); err == nil {
+		t.Fatal("DownloadFile() error = nil, want error")
+	}
+
+	if _, err = os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DownloadFile() created the local file, stat error = %v", err)
+	}
+
+	got, err := os.ReadFile(name + bunnystorage.PartialFileSuffix)
+	if err != nil {
+		t.Fatalf("DownloadFile() removed the partial file: %v", err)
+	}
+
+	if !bytes.Equal(got, content[:len(got)]) || len(got) == 0 {
+		t.Errorf("DownloadFile() kept %d bytes of partial file, want a prefix of the content", len(got))
+	}
+}
+
+// failingDownloads returns a middleware cutting the first file download short
+// after n bytes, and failing every following one before its first byte.
+func failingDownloads(n int64) bunnystorage.Middleware {
+	var served atomic.Int64
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil || req.Method != http.MethodGet || strings.HasSuffix(req.URL.Path, "/") {
+				return resp, err
+			}
+
+			limit := n
+			if served.Add(1) > 1 {
+				limit = 0
+			}
+
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.MultiReader(io.LimitReader(resp.Body, limit), failingReader{}),
+				Closer: resp.Body,
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+func TestClient_DownloadFile_NoOverwrite(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("remote content")
+	existing := []byte("my local notes")
+
+	tests := []struct {
+		name          string
+		existing      bool
+		createDuring  bool
+		wantDownloads int64
+		wantPartial   bool
+		wantErr       error
+		want          []byte
+	}{
+		{
+			name:          "new file",
+			wantDownloads: 1,
+			want:          content,
+		},
+		{
+			name:          "existing file",
+			existing:      true,
+			wantDownloads: 0,
+			wantErr:       os.ErrExist,
+			want:          existing,
+		},
+		{
+			name:          "file created during the download",
+			createDuring:  true,
+			wantDownloads: 1,
+			wantPartial:   true,
+			wantErr:       os.ErrExist,
+			want:          existing,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.PutFile("file.bin", content)
+
+			name := filepath.Join(t.TempDir(), "file.bin")
+
+			var downloads atomic.Int64
+
+			cfg := srv.Config()
+			cfg.Middleware = []bunnystorage.Middleware{
+				func(next http.RoundTripper) http.RoundTripper {
+					return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						if req.Method == http.MethodGet && !strings.HasSuffix(req.URL.Path, "/") {
+							downloads.Add(1)
+
+							if tt.createDuring {
+								if err := os.WriteFile(name, existing, 0o600); err != nil {
+									t.Error(err)
+								}
+							}
+						}
+
+						return next.RoundTrip(req)
+					})
+				},
+			}
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			if tt.existing {
+				if err = os.WriteFile(name, existing, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err = client.DownloadFile(context.Background(), "/", "file.bin", name, bunnystorage.WithNoOverwrite())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DownloadFile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := downloads.Load(); got != tt.wantDownloads {
+				t.Errorf("DownloadFile() made %d downloads, want %d", got, tt.wantDownloads)
+			}
+
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("local file = %q, want %q", got, tt.want)
+			}
+
+			_, err = os.Stat(name + bunnystorage.PartialFileSuffix)
+			if hasPartial := err == nil; hasPartial != tt.wantPartial {
+				t.Errorf("DownloadFile() kept the partial file = %v, want %v", hasPartial, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestClient_DownloadFile_NotFound(t *testing.T) {
+	t.Parallel()
+
+	client, _ := bunnystoragetest.NewClient(t)
+
+	name := filepath.Join(t.TempDir(), "missing.bin")
+
+	_, err := client.DownloadFile(context.Background(), "/", "missing.bin", name)
+	if !errors.Is(err, bunnystorage.ErrNotFound) {
+		t.Errorf("DownloadFile() error = %v, want %v", err, bunnystorage.ErrNotFound)
+	}
+
+	if _, err = os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DownloadFile() created the local file, stat error = %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package bunnystorage
 
-import "net/url"
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
 
 // The following endpoints are available for use with the Edge Storage API.
 const (
@@ -19,37 +23,44 @@ const (
 // Endpoint represents the primary storage region of a storage zone.
 type Endpoint int
 
-// Parse parses a string representation of an endpoint into an Endpoint. If the
-// string is not a valid endpoint, EndpointFalkenstein is returned.
-func Parse(s string) Endpoint {
-	uri, err := url.Parse(s)
-	if err != nil {
-		return EndpointFalkenstein
+// Parse parses a URL or host name of the Edge Storage API into an Endpoint. If
+// the string does not match a known endpoint, ErrInvalidEndpoint is returned;
+// use Config.BaseURL to target hosts without a predefined Endpoint.
+func Parse(s string) (Endpoint, error) {
+	host := s
+
+	if strings.Contains(s, "://") {
+		uri, err := url.Parse(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrInvalidEndpoint, err)
+		}
+
+		host = uri.Host
 	}
 
-	switch uri.Host {
+	switch strings.ToLower(host) {
 	case "storage.bunnycdn.com":
-		return EndpointFalkenstein
+		return EndpointFalkenstein, nil
 	case "ny.storage.bunnycdn.com":
-		return EndpointNewYork
+		return EndpointNewYork, nil
 	case "la.storage.bunnycdn.com":
-		return EndpointLosAngeles
+		return EndpointLosAngeles, nil
 	case "sg.storage.bunnycdn.com":
-		return EndpointSingapore
+		return EndpointSingapore, nil
 	case "syd.storage.bunnycdn.com":
-		return EndpointSydney
+		return EndpointSydney, nil
 	case "uk.storage.bunnycdn.com":
-		return EndpointLondon
+		return EndpointLondon, nil
 	case "se.storage.bunnycdn.com":
-		return EndpointStockholm
+		return EndpointStockholm, nil
 	case "br.storage.bunnycdn.com":
-		return EndpointSaoPaulo
+		return EndpointSaoPaulo, nil
 	case "jh.storage.bunnycdn.com":
-		return EndpointJohannesburg
+		return EndpointJohannesburg, nil
 	case "localhost:62769":
-		return EndpointLocalhost
+		return EndpointLocalhost, nil
 	default:
-		return EndpointFalkenstein
+		return 0, fmt.Errorf("%w: %q", ErrInvalidEndpoint, s)
 	}
 }
 
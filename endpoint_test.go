@@ -1,6 +1,7 @@
 package bunnystorage_test
 
 import (
+	"errors"
 	"testing"
 
 	"git.sr.ht/~jamesponddotco/bunnystorage-go"
@@ -13,11 +14,24 @@ func TestParse(t *testing.T) {
 		name     string
 		input    string
 		expected bunnystorage.Endpoint
+		wantErr  bool
 	}{
 		{
-			name:     "default case",
+			name:     "unknown host",
 			input:    "http://default.com",
-			expected: bunnystorage.EndpointFalkenstein,
+			expected: 0,
+			wantErr:  true,
+		},
+		{
+			name:     "typo",
+			input:    "https://ny.storage.bunnycnd.com",
+			expected: 0,
+			wantErr:  true,
+		},
+		{
+			name:     "bare host",
+			input:    "uk.storage.bunnycdn.com",
+			expected: bunnystorage.EndpointLondon,
 		},
 		{
 			name:     "storage.bunnycdn.com",
@@ -72,7 +86,14 @@ func TestParse(t *testing.T) {
 		{
 			name:     "invalid url",
 			input:    "://invalid.url",
-			expected: bunnystorage.EndpointFalkenstein,
+			expected: 0,
+			wantErr:  true,
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: 0,
+			wantErr:  true,
 		},
 	}
 
@@ -82,7 +103,15 @@ func TestParse(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := bunnystorage.Parse(tt.input)
+			result, err := bunnystorage.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, bunnystorage.ErrInvalidEndpoint) {
+				t.Errorf("Parse() error = %v, want %v", err, bunnystorage.ErrInvalidEndpoint)
+			}
+
 			if result != tt.expected {
 				t.Errorf("got %v, want %v", result, tt.expected)
 			}
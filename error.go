@@ -0,0 +1,108 @@
+package bunnystorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// Sentinel errors matched by APIError through errors.Is.
+const (
+	// ErrBadRequest is returned when the API rejects a request as malformed.
+	ErrBadRequest xerrors.Error = "bad request"
+
+	// ErrUnauthorized is returned when the API rejects the access key.
+	ErrUnauthorized xerrors.Error = "unauthorized"
+
+	// ErrNotFound is returned when the requested object does not exist.
+	ErrNotFound xerrors.Error = "not found"
+
+	// ErrChecksumMismatch is returned when the checksum sent with an upload
+	// does not match the uploaded content, or when downloaded content does not
+	// match the expected checksum.
+	ErrChecksumMismatch xerrors.Error = "checksum mismatch"
+
+	// ErrTooManyRequests is returned when the API rate limits the client.
+	ErrTooManyRequests xerrors.Error = "too many requests"
+
+	// ErrServerError is returned when the API fails with a 5xx status code.
+	ErrServerError xerrors.Error = "server error"
+)
+
+// maxErrorBodySize is the maximum number of bytes read from the body of an
+// error response.
+const maxErrorBodySize int64 = 64 << 10
+
+// APIError represents a non-2xx response from the Edge Storage API.
+type APIError struct {
+	// Method is the HTTP method of the failed request.
+	Method string
+
+	// URL is the URL of the failed request.
+	URL string
+
+	// Message is the error message returned by the API, if any.
+	Message string
+
+	// Status is the HTTP status code of the response.
+	Status int
+}
+
+// apiErrorBody is the error document returned by the Edge Storage API.
+type apiErrorBody struct {
+	Message  string `json:"Message"`
+	HTTPCode int    `json:"HttpCode"`
+}
+
+// newAPIError creates an APIError for the given request, status code and
+// response body.
+func newAPIError(req *http.Request, status int, body []byte) *APIError {
+	var doc apiErrorBody
+
+	message := http.StatusText(status)
+
+	if err := json.Unmarshal(body, &doc); err == nil && doc.Message != "" {
+		message = doc.Message
+	}
+
+	return &APIError{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Message: message,
+		Status:  status,
+	}
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return e.Method + " " + e.URL + ": " + strconv.Itoa(e.Status) + " " + e.Message
+}
+
+// Unwrap returns the sentinel error matching the status code of the response,
+// or nil if there is none.
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.Status == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Message), "checksum"):
+		return ErrChecksumMismatch
+	case e.Status == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.Status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.Status == http.StatusNotFound:
+		return ErrNotFound
+	case e.Status == http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case e.Status >= http.StatusInternalServerError:
+		return ErrServerError
+	default:
+		return nil
+	}
+}
+
+// isSuccess returns true if the status code is in the 2xx range.
+func isSuccess(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
@@ -0,0 +1,104 @@
+package bunnystorage_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		give    *bunnystorage.APIError
+		want    error
+		notWant error
+	}{
+		{
+			name: "bad request",
+			give: &bunnystorage.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid path",
+			},
+			want:    bunnystorage.ErrBadRequest,
+			notWant: bunnystorage.ErrChecksumMismatch,
+		},
+		{
+			name: "checksum mismatch",
+			give: &bunnystorage.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "Checksum and file hash do not match",
+			},
+			want:    bunnystorage.ErrChecksumMismatch,
+			notWant: bunnystorage.ErrBadRequest,
+		},
+		{
+			name: "unauthorized",
+			give: &bunnystorage.APIError{
+				Status: http.StatusUnauthorized,
+			},
+			want:    bunnystorage.ErrUnauthorized,
+			notWant: bunnystorage.ErrNotFound,
+		},
+		{
+			name: "not found",
+			give: &bunnystorage.APIError{
+				Status: http.StatusNotFound,
+			},
+			want:    bunnystorage.ErrNotFound,
+			notWant: bunnystorage.ErrUnauthorized,
+		},
+		{
+			name: "too many requests",
+			give: &bunnystorage.APIError{
+				Status: http.StatusTooManyRequests,
+			},
+			want:    bunnystorage.ErrTooManyRequests,
+			notWant: bunnystorage.ErrServerError,
+		},
+		{
+			name: "server error",
+			give: &bunnystorage.APIError{
+				Status: http.StatusBadGateway,
+			},
+			want:    bunnystorage.ErrServerError,
+			notWant: bunnystorage.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if !errors.Is(tt.give, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", tt.give, tt.want)
+			}
+
+			if errors.Is(tt.give, tt.notWant) {
+				t.Errorf("errors.Is(%v, %v) = true, want false", tt.give, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestAPIError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &bunnystorage.APIError{
+		Method:  http.MethodGet,
+		URL:     "https://storage.bunnycdn.com/zone/file.txt",
+		Message: "Object Not Found",
+		Status:  http.StatusNotFound,
+	}
+
+	want := "GET https://storage.bunnycdn.com/zone/file.txt: 404 Object Not Found"
+
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
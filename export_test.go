@@ -0,0 +1,9 @@
+package bunnystorage
+
+import "time"
+
+// SetClock makes the clients created from cfg read the current time from now
+// instead of time.Now.
+func SetClock(cfg *Config, now func() time.Time) {
+	cfg.now = now
+}
@@ -0,0 +1,205 @@
+package bunnystorage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// region is a storage region serving read requests, with its health state.
+type region struct {
+	// baseURL is the base URL of the region, without a trailing slash.
+	baseURL string
+
+	// downUntil is the time until which the region is skipped after a
+	// failure.
+	downUntil time.Time
+
+	// mu protects downUntil.
+	mu sync.Mutex
+}
+
+// healthy reports whether the region is not cooling down after a failure.
+func (r *region) healthy(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !now.Before(r.downUntil)
+}
+
+// fail marks the region as unhealthy until the cooldown ends.
+func (r *region) fail(now time.Time, cooldown time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.downUntil = now.Add(cooldown)
+}
+
+// succeed marks the region as healthy.
+func (r *region) succeed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.downUntil = time.Time{}
+}
+
+// newRegions returns the regions of the given base URLs, in order.
+func newRegions(baseURLs []string) []*region {
+	regions := make([]*region, 0, len(baseURLs))
+
+	for _, baseURL := range baseURLs {
+		regions = append(regions, &region{baseURL: baseURL})
+	}
+
+	return regions
+}
+
+// failoverTransport is an http.RoundTripper that sends read requests to the
+// first healthy region, moving on to the next one when a region fails with a
+// network error or a 5xx status code. Write requests are always sent to the
+// primary region.
+type failoverTransport struct {
+	next     http.RoundTripper
+	logger   *slog.Logger
+	regions  []*region
+	now      func() time.Time
+	cooldown time.Duration
+}
+
+// newFailoverTransport wraps next with read failover to the fallback regions
+// of the given Config, or returns next unchanged if it has none.
+func newFailoverTransport(next http.RoundTripper, cfg *Config) http.RoundTripper {
+	fallbacks := cfg.fallbackURLs()
+	if len(fallbacks) == 0 {
+		return next
+	}
+
+	return &failoverTransport{
+		next:     next,
+		logger:   cfg.Logger,
+		regions:  newRegions(append([]string{cfg.baseURL()}, fallbacks...)),
+		now:      cfg.now,
+		cooldown: cfg.FailoverCooldown,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if operationFor(req.Method) == OperationWrite {
+		return t.next.RoundTrip(req) //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+	}
+
+	var (
+		ctx     = req.Context()
+		primary = t.regions[0].baseURL
+		resp    *http.Response
+		err     error
+	)
+
+	for _, r := range t.candidates(t.now()) {
+		if resp != nil {
+			drainBody(resp)
+		}
+
+		regionReq, rebaseErr := rebaseRequest(req, primary, r.baseURL)
+		if rebaseErr != nil {
+			return nil, rebaseErr
+		}
+
+		resp, err = t.next.RoundTrip(regionReq)
+
+		if !isRegionFailure(ctx, resp, err) {
+			r.succeed()
+
+			return resp, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+		}
+
+		r.fail(t.now(), t.cooldown)
+		t.log(ctx, r, resp, err)
+	}
+
+	return resp, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+}
+
+// candidates returns the healthy regions in order, or every region if none is
+// healthy, so requests are never refused outright.
+func (t *failoverTransport) candidates(now time.Time) []*region {
+	candidates := make([]*region, 0, len(t.regions))
+
+	for _, r := range t.regions {
+		if r.healthy(now) {
+			candidates = append(candidates, r)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return t.regions
+	}
+
+	return candidates
+}
+
+// log records a region failing a request.
+func (t *failoverTransport) log(ctx context.Context, r *region, resp *http.Response, err error) {
+	if t.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("region", r.baseURL),
+		slog.Duration("cooldown", t.cooldown),
+	}
+
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	t.logger.LogAttrs(ctx, slog.LevelWarn, "storage region failed", attrs...)
+}
+
+// isRegionFailure reports whether a read request that produced the given
+// response and error should be sent to another region.
+func isRegionFailure(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// rebaseRequest returns a copy of req with the base URL from replaced by to,
+// or req itself if both are the same.
+func rebaseRequest(req *http.Request, from, to string) (*http.Request, error) {
+	if from == to {
+		return req, nil
+	}
+
+	uri := req.URL.String()
+	if !strings.HasPrefix(uri, from) {
+		return req, nil
+	}
+
+	rebased, err := url.Parse(to + strings.TrimPrefix(uri, from))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.URL = rebased
+	clone.Host = ""
+
+	return clone, nil
+}
@@ -0,0 +1,288 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+// regionTransport is a transport replying with the status code configured for
+// the host of each request, counting the requests every host receives.
+type regionTransport struct {
+	statuses map[string]int
+	requests map[string]int
+	mu       sync.Mutex
+}
+
+func newRegionTransport(statuses map[string]int) *regionTransport {
+	return &regionTransport{
+		statuses: statuses,
+		requests: make(map[string]int),
+	}
+}
+
+func (r *regionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests[req.URL.Host]++
+
+	return &http.Response{
+		StatusCode: r.statuses[req.URL.Host],
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func (r *regionTransport) count(host string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.requests[host]
+}
+
+func (r *regionTransport) setStatus(host string, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.statuses[host] = status
+}
+
+func TestConfig_Fallbacks(t *testing.T) {
+	t.Parallel()
+
+	primary := bunnystoragetest.NewServer()
+	primary.Close()
+
+	fallback := bunnystoragetest.NewServer()
+	defer fallback.Close()
+
+	fallback.PutFile("docs/readme.txt", []byte("Hello, tester!"))
+
+	cfg := primary.Config()
+	cfg.FallbackBaseURLs = []string{fallback.URL + "/"}
+	cfg.RetryPolicy = &bunnystorage.RetryPolicy{
+		MinDelay: time.Millisecond,
+		MaxDelay: time.Millisecond,
+	}
+
+	client, err := bunnystorage.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	objects, _, err := client.List(ctx, "/docs")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(objects) != 1 || objects[0].ObjectName != "readme.txt" {
+		t.Errorf("List() = %v, want readme.txt", objects)
+	}
+
+	content, _, err := client.Download(ctx, "/docs", "readme.txt")
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	if string(content) != "Hello, tester!" {
+		t.Errorf("Download() = %q, want %q", content, "Hello, tester!")
+	}
+
+	if _, _, err = client.Stat(ctx, "/docs", "readme.txt"); err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	if _, err = client.Upload(ctx, "/docs", "new.txt", "", bytes.NewReader([]byte("new"))); err == nil {
+		t.Error("Upload() error = nil, want the error of the primary region")
+	}
+
+	if _, ok := fallback.File("docs/new.txt"); ok {
+		t.Error("Upload() wrote to the fallback region")
+	}
+}
+
+func TestConfig_Fallbacks_Failover(t *testing.T) {
+	t.Parallel()
+
+	const (
+		primary   = "storage.invalid"
+		secondary = "secondary.invalid"
+		tertiary  = "tertiary.invalid"
+	)
+
+	tests := []struct {
+		name         string
+		statuses     map[string]int
+		write        bool
+		wantErr      error
+		wantRequests map[string]int
+	}{
+		{
+			name:         "healthy primary",
+			statuses:     map[string]int{primary: http.StatusOK, secondary: http.StatusOK},
+			wantRequests: map[string]int{primary: 1, secondary: 0},
+		},
+		{
+			name:         "server error",
+			statuses:     map[string]int{primary: http.StatusBadGateway, secondary: http.StatusOK},
+			wantRequests: map[string]int{primary: 1, secondary: 1},
+		},
+		{
+			name: "in order",
+			statuses: map[string]int{
+				primary:   http.StatusServiceUnavailable,
+				secondary: http.StatusInternalServerError,
+				tertiary:  http.StatusOK,
+			},
+			wantRequests: map[string]int{primary: 1, secondary: 1, tertiary: 1},
+		},
+		{
+			name:         "client error",
+			statuses:     map[string]int{primary: http.StatusNotFound, secondary: http.StatusOK},
+			wantErr:      bunnystorage.ErrNotFound,
+			wantRequests: map[string]int{primary: 1, secondary: 0},
+		},
+		{
+			name:         "write",
+			statuses:     map[string]int{primary: http.StatusBadRequest, secondary: http.StatusOK},
+			write:        true,
+			wantErr:      bunnystorage.ErrBadRequest,
+			wantRequests: map[string]int{primary: 1, secondary: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := newRegionTransport(tt.statuses)
+			client := newTransportClient(t, &bunnystorage.Config{
+				Transport:        rt,
+				FallbackBaseURLs: []string{"http://" + secondary, "http://" + tertiary},
+			})
+
+			var err error
+
+			if tt.write {
+				_, err = client.Upload(context.Background(), "/", "file.txt", "", strings.NewReader("content"))
+			} else {
+				_, _, err = client.List(context.Background(), "/")
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			for host, want := range tt.wantRequests {
+				if got := rt.count(host); got != want {
+					t.Errorf("%s received %d requests, want %d", host, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_FailoverCooldown(t *testing.T) {
+	t.Parallel()
+
+	const (
+		primary   = "storage.invalid"
+		secondary = "secondary.invalid"
+	)
+
+	rt := newRegionTransport(map[string]int{
+		primary:   http.StatusServiceUnavailable,
+		secondary: http.StatusOK,
+	})
+
+	clock := newFakeClock()
+
+	cfg := &bunnystorage.Config{
+		Transport:        rt,
+		FallbackBaseURLs: []string{"http://" + secondary},
+		FailoverCooldown: time.Minute,
+	}
+
+	bunnystorage.SetClock(cfg, clock.Now)
+
+	client := newTransportClient(t, cfg)
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := client.List(ctx, "/"); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+	}
+
+	if got := rt.count(primary); got != 1 {
+		t.Errorf("primary received %d requests during the cooldown, want 1", got)
+	}
+
+	rt.setStatus(primary, http.StatusOK)
+	clock.Advance(time.Minute)
+
+	if _, _, err := client.List(ctx, "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if got := rt.count(primary); got != 2 {
+		t.Errorf("primary received %d requests after the cooldown, want 2", got)
+	}
+
+	if got := rt.count(secondary); got != 3 {
+		t.Errorf("secondary received %d requests, want 3", got)
+	}
+}
+
+func TestConfig_Fallbacks_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  *bunnystorage.Config
+		wantErr error
+	}{
+		{
+			name:    "invalid endpoint",
+			config:  &bunnystorage.Config{Fallbacks: []bunnystorage.Endpoint{bunnystorage.Endpoint(100)}},
+			wantErr: bunnystorage.ErrInvalidEndpoint,
+		},
+		{
+			name:    "invalid base URL",
+			config:  &bunnystorage.Config{FallbackBaseURLs: []string{"ftp://fallback.invalid"}},
+			wantErr: bunnystorage.ErrInvalidBaseURL,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.config.StorageZone = "zone"
+			tt.config.Key = "key"
+			tt.config.Endpoint = bunnystorage.EndpointFalkenstein
+
+			if _, err := bunnystorage.NewClient(tt.config); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewClient() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
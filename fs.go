@@ -0,0 +1,379 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrIsDirectory is returned when reading the contents of a directory opened
+// through FS as if it were a file.
+const ErrIsDirectory xerrors.Error = "is a directory"
+
+// Compile-time checks to ensure FS implements the io/fs interfaces.
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ io.Seeker     = (*file)(nil)
+)
+
+// FS provides read-only access to a storage zone through the [io/fs]
+// interfaces, so it can be used with functions such as [fs.WalkDir],
+// [fs.Glob], [net/http.FS] and [html/template.ParseFS].
+//
+// Paths are relative to the root of the storage zone, following the rules of
+// [fs.ValidPath].
+type FS struct {
+	// ctx is the context used for every request, since the io/fs interfaces
+	// do not accept one.
+	ctx context.Context //nolint:containedctx // io/fs methods take no context.
+
+	// client is the API client used to access the storage zone.
+	client *Client
+}
+
+// FS returns an FS backed by the storage zone. The given context is used for
+// every request made through it.
+func (c *Client) FS(ctx context.Context) *FS {
+	return &FS{
+		ctx:    ctx,
+		client: c,
+	}
+}
+
+// Open opens the named file or directory.
+func (f *FS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &dirFile{
+			fsys: f,
+			name: name,
+			info: info,
+		}, nil
+	}
+
+	return &file{
+		fsys: f,
+		name: name,
+		info: info,
+	}, nil
+}
+
+// ReadDir reads the named directory and returns a list of directory entries
+// sorted by filename.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := f.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return f.readDir(name)
+}
+
+// Stat returns a FileInfo describing the named file or directory.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	return f.stat("stat", name)
+}
+
+// ReadFile reads the named file and returns its contents.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: ErrIsDirectory}
+	}
+
+	dir, base := path.Split(name)
+
+	body, _, err := f.client.Download(f.ctx, dir, base)
+	if err != nil {
+		return nil, pathError("readfile", name, err)
+	}
+
+	return body, nil
+}
+
+// stat returns the fileInfo of the named object by looking it up in the
+// listing of its parent directory.
+func (f *FS) stat(op, name string) (*fileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return &fileInfo{obj: f.client.directoryObject(".")}, nil
+	}
+
+	objects, _, err := f.client.List(f.ctx, apiPath(path.Dir(name)))
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+
+	base := path.Base(name)
+
+	for _, obj := range objects {
+		if obj.ObjectName == base {
+			return &fileInfo{obj: obj}, nil
+		}
+	}
+
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// readDir lists the named directory and returns its entries sorted by name.
+func (f *FS) readDir(name string) ([]fs.DirEntry, error) {
+	objects, _, err := f.client.List(f.ctx, apiPath(name))
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+
+	entries := make([]fs.DirEntry, 0, len(objects))
+
+	for _, obj := range objects {
+		entries = append(entries, &fileInfo{obj: obj})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+// pathError wraps err in an *fs.PathError, translating ErrNotFound into
+// fs.ErrNotExist.
+func pathError(op, name string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		err = fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// fileInfo implements fs.FileInfo and fs.DirEntry for an Object.
+type fileInfo struct {
+	obj *Object
+}
+
+// Name returns the base name of the object.
+func (fi *fileInfo) Name() string {
+	if fi.obj.ObjectName == "" {
+		return "."
+	}
+
+	return fi.obj.ObjectName
+}
+
+// Size returns the length of the object in bytes.
+func (fi *fileInfo) Size() int64 {
+	return fi.obj.Length
+}
+
+// Mode returns the file mode bits of the object, which is always read-only.
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.obj.IsDirectory {
+		return fs.ModeDir | 0o555
+	}
+
+	return 0o444
+}
+
+// ModTime returns the time the object was last changed.
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.obj.LastChangedTime()
+}
+
+// IsDir reports whether the object is a directory.
+func (fi *fileInfo) IsDir() bool {
+	return fi.obj.IsDirectory
+}
+
+// Sys returns the underlying *Object.
+func (fi *fileInfo) Sys() any {
+	return fi.obj
+}
+
+// Type returns the type bits of the object.
+func (fi *fileInfo) Type() fs.FileMode {
+	return fi.Mode().Type()
+}
+
+// Info returns the fileInfo itself.
+func (fi *fileInfo) Info() (fs.FileInfo, error) {
+	return fi, nil
+}
+
+// String returns a human-readable representation of the entry.
+func (fi *fileInfo) String() string {
+	return fs.FormatFileInfo(fi)
+}
+
+// file is a regular file opened through FS. Its contents are streamed from
+// the storage zone on the first read, starting at the current offset.
+type file struct {
+	fsys   *FS
+	info   *fileInfo
+	body   io.ReadCloser
+	name   string
+	offset int64
+}
+
+// Stat returns the FileInfo of the file.
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// Read reads up to len(p) bytes of the file into p.
+func (f *file) Read(p []byte) (int, error) {
+	if f.body == nil {
+		if f.offset > 0 && f.offset >= f.info.Size() {
+			return 0, io.EOF
+		}
+
+		var opts []DownloadOption
+		if f.offset > 0 {
+			opts = append(opts, WithRange(f.offset, -1))
+		}
+
+		dir, base := path.Split(f.name)
+
+		body, _, err := f.fsys.client.DownloadStream(f.fsys.ctx, dir, base, opts...)
+		if err != nil {
+			return 0, pathError("read", f.name, err)
+		}
+
+		f.body = body
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, &fs.PathError{Op: "read", Path: f.name, Err: err}
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned unwrapped.
+}
+
+// Seek sets the offset for the next Read. When the offset changes, the open
+// stream is closed and the next Read requests the rest of the file from the
+// new offset with a ranged download.
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset == f.offset {
+		return offset, nil
+	}
+
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+
+	f.body = nil
+	f.offset = offset
+
+	return offset, nil
+}
+
+// Close closes the file.
+func (f *file) Close() error {
+	if f.body == nil {
+		return nil
+	}
+
+	if err := f.body.Close(); err != nil {
+		return &fs.PathError{Op: "close", Path: f.name, Err: err}
+	}
+
+	return nil
+}
+
+// dirFile is a directory opened through FS.
+type dirFile struct {
+	fsys    *FS
+	info    *fileInfo
+	name    string
+	entries []fs.DirEntry
+	offset  int
+	loaded  bool
+}
+
+// Stat returns the FileInfo of the directory.
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+// Read always fails, since directories cannot be read as files.
+func (d *dirFile) Read(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: ErrIsDirectory}
+}
+
+// Close closes the directory.
+func (*dirFile) Close() error {
+	return nil
+}
+
+// ReadDir reads the contents of the directory and returns up to n entries in
+// directory order, following the semantics of fs.ReadDirFile.
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		entries, err := d.fsys.readDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+
+		d.entries = entries
+		d.loaded = true
+	}
+
+	remaining := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+
+	d.offset += n
+
+	return remaining[:n], nil
+}
@@ -0,0 +1,261 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/internal/testutil"
+)
+
+// fsHandler returns a handler serving the given files, keyed by their path
+// relative to the root of the mock storage zone.
+func fsHandler(t *testing.T, files map[string]string) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/mock/")
+
+		if !strings.HasSuffix(name, "/") && name != "" {
+			content, ok := files[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			if _, err := w.Write([]byte(content)); err != nil {
+				t.Errorf("Write() error = %v", err)
+			}
+
+			return
+		}
+
+		var (
+			seen    = make(map[string]bool)
+			objects = make([]*bunnystorage.Object, 0)
+		)
+
+		for key, content := range files {
+			rest, ok := strings.CutPrefix(key, name)
+			if !ok {
+				continue
+			}
+
+			entry, _, isDir := strings.Cut(rest, "/")
+			if seen[entry] {
+				continue
+			}
+
+			seen[entry] = true
+
+			obj := &bunnystorage.Object{
+				Path:            "/mock/" + name,
+				ObjectName:      entry,
+				StorageZoneName: "mock",
+				LastChanged:     "2023-04-20T15:32:08.004",
+				IsDirectory:     isDir,
+			}
+
+			if !isDir {
+				obj.Length = int64(len(content))
+			}
+
+			objects = append(objects, obj)
+		}
+
+		sort.Slice(objects, func(i, j int) bool {
+			return objects[i].ObjectName > objects[j].ObjectName
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(objects); err != nil {
+			t.Errorf("Encode() error = %v", err)
+		}
+	}
+}
+
+func TestFS(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+		ctx           = context.Background()
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	files := map[string]string{
+		"hello.txt":             "Hello, tester!",
+		"images/logo.svg":       "<svg></svg>",
+		"images/icons/home.svg": "<svg><path/></svg>",
+		"empty.txt":             "",
+	}
+
+	mux.HandleFunc("/mock/", fsHandler(t, files))
+
+	fsys := client.FS(ctx)
+
+	if err := fstest.TestFS(fsys, "hello.txt", "images/logo.svg", "images/icons/home.svg", "empty.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFS_HTTP(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+	srv.PutFile("hello.txt", []byte("Hello, range world!"))
+	srv.PutFile("notes.unknown", []byte("plain notes without an extension type"))
+
+	handler := http.FileServer(http.FS(client.FS(context.Background())))
+
+	tests := []struct {
+		name       string
+		target     string
+		rangeValue string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "middle_range",
+			target:     "/hello.txt",
+			rangeValue: "bytes=7-11",
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "range",
+		},
+		{
+			name:       "suffix_range",
+			target:     "/hello.txt",
+			rangeValue: "bytes=-6",
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "world!",
+		},
+		{
+			name:       "unknown_content_type",
+			target:     "/notes.unknown",
+			wantStatus: http.StatusOK,
+			wantBody:   "plain notes without an extension type",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			if tt.rangeValue != "" {
+				req.Header.Set("Range", tt.rangeValue)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("ServeHTTP() body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestFS_Errors(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+		ctx           = context.Background()
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	mux.HandleFunc("/mock/", fsHandler(t, map[string]string{
+		"dir/file.txt": "content",
+	}))
+
+	fsys := client.FS(ctx)
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "open_missing",
+			call: func() error {
+				_, err := fsys.Open("missing.txt")
+
+				return err
+			},
+			wantErr: fs.ErrNotExist,
+		},
+		{
+			name: "open_invalid",
+			call: func() error {
+				_, err := fsys.Open("/dir/file.txt")
+
+				return err
+			},
+			wantErr: fs.ErrInvalid,
+		},
+		{
+			name: "read_file_missing",
+			call: func() error {
+				_, err := fsys.ReadFile("dir/missing.txt")
+
+				return err
+			},
+			wantErr: fs.ErrNotExist,
+		},
+		{
+			name: "read_dir_on_file",
+			call: func() error {
+				_, err := fsys.ReadDir("dir/file.txt")
+
+				return err
+			},
+			wantErr: fs.ErrInvalid,
+		},
+		{
+			name: "read_directory",
+			call: func() error {
+				f, err := fsys.Open("dir")
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				_, err = f.Read(make([]byte, 1))
+
+				return err
+			},
+			wantErr: bunnystorage.ErrIsDirectory,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
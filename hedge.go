@@ -0,0 +1,249 @@
+package bunnystorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// HedgePolicy configures hedged reads: when the primary region has not
+// responded to a read request within Delay, the same request is sent to the
+// first fallback region, the first successful response is used and the other
+// request is canceled.
+type HedgePolicy struct {
+	// Delay is the time to wait for the primary region before sending the
+	// hedged request. It should be close to the tail latency of the primary
+	// region, such as its 95th percentile.
+	Delay time.Duration
+}
+
+// validate returns an error if p is set without a delay or without a fallback
+// region to send hedged requests to. It accepts a nil policy.
+func (p *HedgePolicy) validate(fallbacks []string) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.Delay <= 0 {
+		return fmt.Errorf("%w: hedge delay %v", ErrInvalidConfig, p.Delay)
+	}
+
+	if len(fallbacks) == 0 {
+		return fmt.Errorf("%w: hedging requires a fallback region", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// HedgeStats reports how hedged reads performed since the client was created.
+type HedgeStats struct {
+	// Reads is the number of read requests sent, counting every attempt.
+	Reads int64
+
+	// Hedged is the number of read requests for which a hedged request was
+	// sent because the primary region was too slow.
+	Hedged int64
+
+	// Wins is the number of hedged requests whose response was used.
+	Wins int64
+}
+
+// hedgeCounters holds the counters behind HedgeStats.
+type hedgeCounters struct {
+	reads  atomic.Int64
+	hedged atomic.Int64
+	wins   atomic.Int64
+}
+
+// stats returns a snapshot of the counters.
+func (c *hedgeCounters) stats() HedgeStats {
+	return HedgeStats{
+		Reads:  c.reads.Load(),
+		Hedged: c.hedged.Load(),
+		Wins:   c.wins.Load(),
+	}
+}
+
+// HedgeStats returns statistics about the hedged reads made by the client,
+// which are all zero unless Config.HedgePolicy is set.
+func (c *Client) HedgeStats() HedgeStats {
+	return c.hedges.stats()
+}
+
+// hedgeTransport is an http.RoundTripper that sends a second copy of slow read
+// requests to a fallback region.
+type hedgeTransport struct {
+	// next sends requests to the primary region, failing over to the
+	// fallback regions if configured.
+	next http.RoundTripper
+
+	// hedge sends hedged requests, which are already rewritten to target the
+	// fallback region.
+	hedge http.RoundTripper
+
+	counters *hedgeCounters
+	primary  string
+	fallback string
+	delay    time.Duration
+}
+
+// newHedgeTransport wraps next with hedged reads according to the given
+// Config, or returns next unchanged if hedging is disabled. Hedged requests are
+// sent through regional, which must not fail over.
+func newHedgeTransport(next, regional http.RoundTripper, cfg *Config, counters *hedgeCounters) http.RoundTripper {
+	fallbacks := cfg.fallbackURLs()
+	if cfg.HedgePolicy == nil || len(fallbacks) == 0 {
+		return next
+	}
+
+	return &hedgeTransport{
+		next:     next,
+		hedge:    regional,
+		counters: counters,
+		primary:  cfg.baseURL(),
+		fallback: fallbacks[0],
+		delay:    cfg.HedgePolicy.Delay,
+	}
+}
+
+// hedgeResult is the outcome of one of the copies of a hedged request.
+type hedgeResult struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+	hedged bool
+}
+
+// ok reports whether the result can be used without waiting for the other
+// copy of the request.
+func (r *hedgeResult) ok() bool {
+	return r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+}
+
+// discard cancels the request and releases its response.
+func (r *hedgeResult) discard() {
+	r.cancel()
+
+	if r.resp != nil {
+		drainBody(r.resp)
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *hedgeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if operationFor(req.Method) != OperationRead {
+		return t.next.RoundTrip(req) //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+	}
+
+	t.counters.reads.Add(1)
+
+	hedgeReq, err := rebaseRequest(req, t.primary, t.fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan *hedgeResult, 2)
+
+	cancelPrimary := t.send(t.next, req, results, false)
+
+	timer := time.NewTimer(t.delay)
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		return t.use(r)
+	case <-timer.C:
+	}
+
+	t.counters.hedged.Add(1)
+
+	cancelHedge := t.send(t.hedge, hedgeReq, results, true)
+
+	first := <-results
+	if !first.ok() {
+		second := <-results
+		if !second.ok() {
+			second.discard()
+
+			return t.use(first)
+		}
+
+		first.discard()
+
+		return t.use(second)
+	}
+
+	// Cancel the slower copy and release it once it completes, in the
+	// background.
+	if first.hedged {
+		cancelPrimary()
+	} else {
+		cancelHedge()
+	}
+
+	go func() {
+		(<-results).discard()
+	}()
+
+	return t.use(first)
+}
+
+// send sends req through rt in a new goroutine with its own context, and
+// delivers the result to results. It returns a function canceling the
+// request.
+func (t *hedgeTransport) send(rt http.RoundTripper, req *http.Request, results chan<- *hedgeResult, hedged bool) context.CancelFunc {
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.Clone(ctx)
+
+	go func() {
+		resp, err := rt.RoundTrip(req)
+
+		results <- &hedgeResult{
+			resp:   resp,
+			err:    err,
+			cancel: cancel,
+			hedged: hedged,
+		}
+	}()
+
+	return cancel
+}
+
+// use returns the response of r, keeping its request alive until the body is
+// closed.
+func (t *hedgeTransport) use(r *hedgeResult) (*http.Response, error) {
+	if r.hedged && r.ok() {
+		t.counters.wins.Add(1)
+	}
+
+	if r.err != nil {
+		r.cancel()
+
+		return nil, r.err
+	}
+
+	r.resp.Body = &cancelBody{
+		ReadCloser: r.resp.Body,
+		cancel:     r.cancel,
+	}
+
+	return r.resp, nil
+}
+
+// cancelBody is a response body that cancels the context of its request when
+// closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err //nolint:wrapcheck // errors from the response body are returned as is.
+}
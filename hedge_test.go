@@ -0,0 +1,250 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// slowRegion describes how a host of latencyTransport replies.
+type slowRegion struct {
+	latency time.Duration
+	status  int
+}
+
+// latencyTransport is a transport replying to each host after its latency,
+// recording which requests were canceled before they completed.
+type latencyTransport struct {
+	regions  map[string]slowRegion
+	requests map[string]int
+	canceled map[string]int
+	mu       sync.Mutex
+}
+
+func newLatencyTransport(regions map[string]slowRegion) *latencyTransport {
+	return &latencyTransport{
+		regions:  regions,
+		requests: make(map[string]int),
+		canceled: make(map[string]int),
+	}
+}
+
+func (l *latencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	l.mu.Lock()
+	region := l.regions[host]
+	l.requests[host]++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(region.latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+		l.mu.Lock()
+		l.canceled[host]++
+		l.mu.Unlock()
+
+		return nil, req.Context().Err()
+	}
+
+	return &http.Response{
+		StatusCode: region.status,
+		Header:     http.Header{"Content-Type": []string{"application/octet-stream"}},
+		Body:       io.NopCloser(strings.NewReader(host)),
+		Request:    req,
+	}, nil
+}
+
+func (l *latencyTransport) counts(host string) (requests, canceled int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.requests[host], l.canceled[host]
+}
+
+func TestConfig_HedgePolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		primary   = "storage.invalid"
+		secondary = "secondary.invalid"
+	)
+
+	tests := []struct {
+		name         string
+		primary      slowRegion
+		secondary    slowRegion
+		want         string
+		wantStats    bunnystorage.HedgeStats
+		wantCanceled string
+	}{
+		{
+			name:      "fast primary",
+			primary:   slowRegion{status: http.StatusOK},
+			secondary: slowRegion{status: http.StatusOK},
+			want:      primary,
+			wantStats: bunnystorage.HedgeStats{Reads: 1},
+		},
+		{
+			name:         "slow primary",
+			primary:      slowRegion{latency: time.Minute, status: http.StatusOK},
+			secondary:    slowRegion{status: http.StatusOK},
+			want:         secondary,
+			wantStats:    bunnystorage.HedgeStats{Reads: 1, Hedged: 1, Wins: 1},
+			wantCanceled: primary,
+		},
+		{
+			name:         "slow primary and slower hedge",
+			primary:      slowRegion{latency: 100 * time.Millisecond, status: http.StatusOK},
+			secondary:    slowRegion{latency: time.Minute, status: http.StatusOK},
+			want:         primary,
+			wantStats:    bunnystorage.HedgeStats{Reads: 1, Hedged: 1},
+			wantCanceled: secondary,
+		},
+		{
+			name:      "failing hedge",
+			primary:   slowRegion{latency: 100 * time.Millisecond, status: http.StatusOK},
+			secondary: slowRegion{status: http.StatusServiceUnavailable},
+			want:      primary,
+			wantStats: bunnystorage.HedgeStats{Reads: 1, Hedged: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := newLatencyTransport(map[string]slowRegion{
+				primary:   tt.primary,
+				secondary: tt.secondary,
+			})
+
+			client := newTransportClient(t, &bunnystorage.Config{
+				Transport:        rt,
+				FallbackBaseURLs: []string{"http://" + secondary},
+				HedgePolicy: &bunnystorage.HedgePolicy{
+					Delay: 10 * time.Millisecond,
+				},
+			})
+
+			content, _, err := client.Download(context.Background(), "/", "file.txt")
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+
+			if string(content) != tt.want {
+				t.Errorf("Download() served by %s, want %s", content, tt.want)
+			}
+
+			if got := client.HedgeStats(); got != tt.wantStats {
+				t.Errorf("HedgeStats() = %+v, want %+v", got, tt.wantStats)
+			}
+
+			if tt.wantCanceled == "" {
+				return
+			}
+
+			// The slower request is canceled in the background, so give it
+			// time to return.
+			deadline := time.Now().Add(time.Second)
+
+			for {
+				_, canceled := rt.counts(tt.wantCanceled)
+				if canceled == 1 {
+					break
+				}
+
+				if time.Now().After(deadline) {
+					t.Fatalf("%s canceled %d requests, want 1", tt.wantCanceled, canceled)
+				}
+
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestConfig_HedgePolicy_Writes(t *testing.T) {
+	t.Parallel()
+
+	const (
+		primary   = "storage.invalid"
+		secondary = "secondary.invalid"
+	)
+
+	rt := newLatencyTransport(map[string]slowRegion{
+		primary:   {latency: 50 * time.Millisecond, status: http.StatusCreated},
+		secondary: {status: http.StatusCreated},
+	})
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport:        rt,
+		FallbackBaseURLs: []string{"http://" + secondary},
+		HedgePolicy: &bunnystorage.HedgePolicy{
+			Delay: time.Millisecond,
+		},
+	})
+
+	if _, err := client.Upload(context.Background(), "/", "file.txt", "", strings.NewReader("content")); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if requests, _ := rt.counts(secondary); requests != 0 {
+		t.Errorf("secondary received %d requests, want 0", requests)
+	}
+
+	if got := client.HedgeStats(); got != (bunnystorage.HedgeStats{}) {
+		t.Errorf("HedgeStats() = %+v, want zero", got)
+	}
+}
+
+func TestConfig_HedgePolicy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config *bunnystorage.Config
+	}{
+		{
+			name: "no fallback",
+			config: &bunnystorage.Config{
+				HedgePolicy: &bunnystorage.HedgePolicy{Delay: time.Second},
+			},
+		},
+		{
+			name: "no delay",
+			config: &bunnystorage.Config{
+				FallbackBaseURLs: []string{"http://secondary.invalid"},
+				HedgePolicy:      &bunnystorage.HedgePolicy{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.config.StorageZone = "zone"
+			tt.config.Key = "key"
+			tt.config.Endpoint = bunnystorage.EndpointFalkenstein
+
+			if _, err := bunnystorage.NewClient(tt.config); !errors.Is(err, bunnystorage.ErrInvalidConfig) {
+				t.Errorf("NewClient() error = %v, want %v", err, bunnystorage.ErrInvalidConfig)
+			}
+		})
+	}
+}
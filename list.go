@@ -0,0 +1,294 @@
+package bunnystorage
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+// SortKey is the field a listing is sorted by.
+type SortKey int
+
+// Sort keys accepted by WithSort.
+const (
+	// SortByName sorts objects by ObjectName.
+	SortByName SortKey = iota
+
+	// SortBySize sorts objects by Length.
+	SortBySize
+
+	// SortByLastChanged sorts objects by LastChanged.
+	SortByLastChanged
+)
+
+// ListOption configures a call to Client.List or Client.ListPages.
+type ListOption func(*listOptions)
+
+// listOptions holds the options of a call to Client.List or Client.ListPages.
+type listOptions struct {
+	since      time.Time
+	before     time.Time
+	pattern    string
+	minSize    int64
+	maxSize    int64
+	sortKey    SortKey
+	sorted     bool
+	reverse    bool
+	filesOnly  bool
+	dirsOnly   bool
+	sizeFilter bool
+}
+
+// WithFilesOnly makes List return files only.
+func WithFilesOnly() ListOption {
+	return func(o *listOptions) {
+		o.filesOnly = true
+		o.dirsOnly = false
+	}
+}
+
+// WithDirectoriesOnly makes List return directories only.
+func WithDirectoriesOnly() ListOption {
+	return func(o *listOptions) {
+		o.dirsOnly = true
+		o.filesOnly = false
+	}
+}
+
+// WithPattern makes List return only the objects whose ObjectName matches the
+// given pattern, using the syntax of [path.Match]. If the pattern is
+// malformed, List returns an error matching [path.ErrBadPattern].
+func WithPattern(pattern string) ListOption {
+	return func(o *listOptions) {
+		o.pattern = pattern
+	}
+}
+
+// WithModifiedSince makes List return only the objects last changed at or
+// after t.
+func WithModifiedSince(t time.Time) ListOption {
+	return func(o *listOptions) {
+		o.since = t
+	}
+}
+
+// WithModifiedBefore makes List return only the objects last changed strictly
+// before t.
+func WithModifiedBefore(t time.Time) ListOption {
+	return func(o *listOptions) {
+		o.before = t
+	}
+}
+
+// WithSizeRange makes List return only the files whose size in bytes is at
+// least minSize and at most maxSize. A negative maxSize sets no upper bound.
+// Directories are not filtered by size.
+func WithSizeRange(minSize, maxSize int64) ListOption {
+	return func(o *listOptions) {
+		o.sizeFilter = true
+		o.minSize = minSize
+		o.maxSize = maxSize
+	}
+}
+
+// WithSort makes List sort the objects by the given key in ascending order,
+// breaking ties by name. Without it, objects are returned in the order of the
+// API response.
+func WithSort(key SortKey) ListOption {
+	return func(o *listOptions) {
+		o.sorted = true
+		o.sortKey = key
+	}
+}
+
+// WithReverse makes List reverse the sort order set by WithSort, or sort by
+// name in descending order if WithSort is not given.
+func WithReverse() ListOption {
+	return func(o *listOptions) {
+		o.sorted = true
+		o.reverse = true
+	}
+}
+
+// newListOptions returns the list options resulting from applying opts.
+func newListOptions(opts []ListOption) (*listOptions, error) {
+	o := &listOptions{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.pattern != "" {
+		if _, err := path.Match(o.pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, o.pattern)
+		}
+	}
+
+	return o, nil
+}
+
+// apply returns the objects matching o, sorted as requested. It filters the
+// given slice in place.
+func (o *listOptions) apply(objects []*Object) []*Object {
+	filtered := objects[:0]
+
+	for _, obj := range objects {
+		if o.match(obj) {
+			filtered = append(filtered, obj)
+		}
+	}
+
+	if o.sorted {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if o.reverse {
+				return o.less(filtered[j], filtered[i])
+			}
+
+			return o.less(filtered[i], filtered[j])
+		})
+	}
+
+	return filtered
+}
+
+// match reports whether obj passes the filters of o.
+func (o *listOptions) match(obj *Object) bool {
+	if (o.filesOnly && obj.IsDirectory) || (o.dirsOnly && !obj.IsDirectory) {
+		return false
+	}
+
+	if o.pattern != "" {
+		if ok, _ := path.Match(o.pattern, obj.ObjectName); !ok {
+			return false
+		}
+	}
+
+	if !o.since.IsZero() || !o.before.IsZero() {
+		changed := obj.LastChangedTime()
+
+		if !o.since.IsZero() && changed.Before(o.since) {
+			return false
+		}
+
+		if !o.before.IsZero() && !changed.Before(o.before) {
+			return false
+		}
+	}
+
+	if o.sizeFilter && !obj.IsDirectory {
+		if obj.Length < o.minSize || (o.maxSize >= 0 && obj.Length > o.maxSize) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// less reports whether a sorts before b according to the sort key of o.
+func (o *listOptions) less(a, b *Object) bool {
+	switch o.sortKey {
+	case SortBySize:
+		if a.Length != b.Length {
+			return a.Length < b.Length
+		}
+	case SortByLastChanged:
+		if at, bt := a.LastChangedTime(), b.LastChangedTime(); !at.Equal(bt) {
+			return at.Before(bt)
+		}
+	case SortByName:
+	}
+
+	return a.ObjectName < b.ObjectName
+}
+
+// ListIterator yields the objects of a directory listing in pages. It is
+// created by Client.ListPages, and is not safe for concurrent use.
+//
+// A typical loop looks like this:
+//
+//	it := client.ListPages(ctx, "/images", 100)
+//	for it.Next() {
+//		for _, obj := range it.Page() {
+//			// ...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type ListIterator struct {
+	ctx      context.Context //nolint:containedctx // the iterator fetches the listing lazily.
+	client   *Client
+	err      error
+	path     string
+	opts     []ListOption
+	objects  []*Object
+	page     []*Object
+	size     int
+	fetched  bool
+	response *Response
+}
+
+// ListPages returns an iterator over the listing of a directory, yielding
+// pages of at most pageSize objects, or a single page if pageSize is not
+// positive. The listing is fetched with List on the first call to Next, and
+// accepts the same options.
+func (c *Client) ListPages(ctx context.Context, path string, pageSize int, opts ...ListOption) *ListIterator {
+	return &ListIterator{
+		ctx:    ctx,
+		client: c,
+		path:   path,
+		opts:   opts,
+		size:   pageSize,
+	}
+}
+
+// Next advances the iterator to the next page, and reports whether there is
+// one. It returns false when the listing is exhausted or fails, in which case
+// Err reports the error.
+func (it *ListIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.fetched {
+		it.fetched = true
+
+		it.objects, it.response, it.err = it.client.List(it.ctx, it.path, it.opts...)
+		if it.err != nil {
+			return false
+		}
+	}
+
+	if len(it.objects) == 0 {
+		it.page = nil
+
+		return false
+	}
+
+	n := len(it.objects)
+	if it.size > 0 && it.size < n {
+		n = it.size
+	}
+
+	it.page, it.objects = it.objects[:n:n], it.objects[n:]
+
+	return true
+}
+
+// Page returns the current page of objects.
+func (it *ListIterator) Page() []*Object {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// Response returns the response of the List call made by the iterator, or
+// nil if it has not been made yet.
+func (it *ListIterator) Response() *Response {
+	return it.response
+}
@@ -0,0 +1,233 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// listingClient returns a client whose listings always return objects.
+func listingClient(t *testing.T, objects []*bunnystorage.Object) *bunnystorage.Client {
+	t.Helper()
+
+	body, err := json.Marshal(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newTransportClient(t, &bunnystorage.Config{
+		Transport: bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Request:    req,
+			}, nil
+		}),
+	})
+}
+
+func listingObjects() []*bunnystorage.Object {
+	return []*bunnystorage.Object{
+		{Path: "/zone/", ObjectName: "b.jpg", Length: 300, LastChanged: "2023-04-02T10:00:00.000"},
+		{Path: "/zone/", ObjectName: "photos", IsDirectory: true, LastChanged: "2023-04-05T10:00:00.000"},
+		{Path: "/zone/", ObjectName: "a.txt", Length: 100, LastChanged: "2023-04-03T10:00:00.000"},
+		{Path: "/zone/", ObjectName: "c.jpg", Length: 200, LastChanged: "2023-04-01T10:00:00.000"},
+		{Path: "/zone/", ObjectName: "d.jpg", Length: 200, LastChanged: "2023-04-04T10:00:00.000"},
+	}
+}
+
+func objectNames(objects []*bunnystorage.Object) []string {
+	names := make([]string, 0, len(objects))
+
+	for _, obj := range objects {
+		names = append(names, obj.ObjectName)
+	}
+
+	return names
+}
+
+func TestClient_List_Options(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2023, 4, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []bunnystorage.ListOption
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "no options",
+			want: []string{"b.jpg", "photos", "a.txt", "c.jpg", "d.jpg"},
+		},
+		{
+			name: "files only",
+			opts: []bunnystorage.ListOption{bunnystorage.WithFilesOnly()},
+			want: []string{"b.jpg", "a.txt", "c.jpg", "d.jpg"},
+		},
+		{
+			name: "directories only",
+			opts: []bunnystorage.ListOption{bunnystorage.WithDirectoriesOnly()},
+			want: []string{"photos"},
+		},
+		{
+			name: "pattern",
+			opts: []bunnystorage.ListOption{bunnystorage.WithPattern("*.jpg")},
+			want: []string{"b.jpg", "c.jpg", "d.jpg"},
+		},
+		{
+			name:    "bad pattern",
+			opts:    []bunnystorage.ListOption{bunnystorage.WithPattern("[")},
+			wantErr: path.ErrBadPattern,
+		},
+		{
+			name: "modified since",
+			opts: []bunnystorage.ListOption{bunnystorage.WithModifiedSince(day(3))},
+			want: []string{"photos", "a.txt", "d.jpg"},
+		},
+		{
+			name: "modified before",
+			opts: []bunnystorage.ListOption{bunnystorage.WithModifiedBefore(day(3))},
+			want: []string{"b.jpg", "c.jpg"},
+		},
+		{
+			name: "size range",
+			opts: []bunnystorage.ListOption{bunnystorage.WithSizeRange(150, 250)},
+			want: []string{"photos", "c.jpg", "d.jpg"},
+		},
+		{
+			name: "minimum size",
+			opts: []bunnystorage.ListOption{bunnystorage.WithFilesOnly(), bunnystorage.WithSizeRange(200, -1)},
+			want: []string{"b.jpg", "c.jpg", "d.jpg"},
+		},
+		{
+			name: "sort by name",
+			opts: []bunnystorage.ListOption{bunnystorage.WithSort(bunnystorage.SortByName)},
+			want: []string{"a.txt", "b.jpg", "c.jpg", "d.jpg", "photos"},
+		},
+		{
+			name: "sort by size",
+			opts: []bunnystorage.ListOption{bunnystorage.WithFilesOnly(), bunnystorage.WithSort(bunnystorage.SortBySize)},
+			want: []string{"a.txt", "c.jpg", "d.jpg", "b.jpg"},
+		},
+		{
+			name: "sort by last changed",
+			opts: []bunnystorage.ListOption{bunnystorage.WithSort(bunnystorage.SortByLastChanged)},
+			want: []string{"c.jpg", "b.jpg", "a.txt", "d.jpg", "photos"},
+		},
+		{
+			name: "reverse",
+			opts: []bunnystorage.ListOption{bunnystorage.WithSort(bunnystorage.SortBySize), bunnystorage.WithReverse()},
+			want: []string{"b.jpg", "d.jpg", "c.jpg", "a.txt", "photos"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := listingClient(t, listingObjects())
+
+			got, _, err := client.List(context.Background(), "/", tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("List() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if names := objectNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("List() = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ListPages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pageSize int
+		opts     []bunnystorage.ListOption
+		want     [][]string
+	}{
+		{
+			name:     "pages",
+			pageSize: 2,
+			opts:     []bunnystorage.ListOption{bunnystorage.WithSort(bunnystorage.SortByName)},
+			want:     [][]string{{"a.txt", "b.jpg"}, {"c.jpg", "d.jpg"}, {"photos"}},
+		},
+		{
+			name:     "single page",
+			pageSize: 0,
+			opts:     []bunnystorage.ListOption{bunnystorage.WithFilesOnly()},
+			want:     [][]string{{"b.jpg", "a.txt", "c.jpg", "d.jpg"}},
+		},
+		{
+			name:     "empty",
+			pageSize: 2,
+			opts:     []bunnystorage.ListOption{bunnystorage.WithPattern("*.png")},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := listingClient(t, listingObjects())
+
+			var got [][]string
+
+			it := client.ListPages(context.Background(), "/", tt.pageSize, tt.opts...)
+			for it.Next() {
+				got = append(got, objectNames(it.Page()))
+			}
+
+			if err := it.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pages = %q, want %q", got, tt.want)
+			}
+
+			if it.Next() {
+				t.Error("Next() = true after the last page")
+			}
+		})
+	}
+}
+
+func TestClient_ListPages_Error(t *testing.T) {
+	t.Parallel()
+
+	client := listingClient(t, nil)
+
+	it := client.ListPages(context.Background(), "/", 10, bunnystorage.WithPattern("["))
+	if it.Next() {
+		t.Fatal("Next() = true, want false")
+	}
+
+	if err := it.Err(); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("Err() = %v, want %v", err, path.ErrBadPattern)
+	}
+}
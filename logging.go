@@ -0,0 +1,190 @@
+package bunnystorage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// redacted replaces the value of sensitive headers in log records.
+const redacted string = "REDACTED"
+
+// attemptKey is the context key holding the attempt number of a request.
+type attemptKey struct{}
+
+// withAttempt returns a copy of ctx carrying the attempt number of a request,
+// starting at 1.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptFrom returns the attempt number carried by ctx, or 1 if there is
+// none.
+func attemptFrom(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+
+	return 1
+}
+
+// loggingTransport is an http.RoundTripper that logs every attempt of a
+// request to Config.Logger.
+type loggingTransport struct {
+	next       http.RoundTripper
+	logger     *slog.Logger
+	level      slog.Leveler
+	errorLevel slog.Leveler
+	errorBody  bool
+}
+
+// newLoggingTransport wraps next with request logging according to the given
+// Config, or returns next unchanged if it has no logger.
+func newLoggingTransport(next http.RoundTripper, cfg *Config) http.RoundTripper {
+	if cfg.Logger == nil {
+		return next
+	}
+
+	t := &loggingTransport{
+		next:       next,
+		logger:     cfg.Logger,
+		level:      cfg.LogLevel,
+		errorLevel: cfg.ErrorLogLevel,
+		errorBody:  cfg.LogErrorBodies,
+	}
+
+	if t.level == nil {
+		t.level = slog.LevelDebug
+	}
+
+	if t.errorLevel == nil {
+		t.errorLevel = slog.LevelWarn
+	}
+
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	level := t.level.Level()
+	if err != nil || !isSuccess(resp.StatusCode) {
+		level = t.errorLevel.Level()
+	}
+
+	if !t.logger.Enabled(ctx, level) {
+		return resp, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", operationName(req)),
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("attempt", attemptFrom(ctx)),
+		slog.Int64("request_bytes", max(req.ContentLength, 0)),
+		slog.Duration("duration", time.Since(start)),
+	}
+
+	debug := t.logger.Enabled(ctx, slog.LevelDebug)
+	if debug {
+		attrs = append(attrs, slog.Any("headers", redactHeaders(req.Header)))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+
+		t.logger.LogAttrs(ctx, level, "request failed", attrs...)
+
+		return nil, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+	}
+
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.Int64("response_bytes", max(resp.ContentLength, 0)),
+	)
+
+	if !isSuccess(resp.StatusCode) && t.errorBody && debug {
+		attrs = append(attrs, slog.String("body", peekBody(resp)))
+	}
+
+	t.logger.LogAttrs(ctx, level, "request completed", attrs...)
+
+	return resp, nil
+}
+
+// operationName returns the name of the client operation performed by req,
+// such as "list" or "upload".
+func operationName(req *http.Request) string {
+	isDir := strings.HasSuffix(req.URL.Path, "/")
+
+	switch req.Method {
+	case http.MethodGet:
+		if isDir {
+			return "list"
+		}
+
+		return "download"
+	case http.MethodHead:
+		return "stat"
+	case http.MethodPut:
+		return "upload"
+	case http.MethodDelete:
+		if isDir {
+			return "delete_directory"
+		}
+
+		return "delete"
+	default:
+		return strings.ToLower(req.Method)
+	}
+}
+
+// redactHeaders returns a copy of header with the values of the AccessKey
+// header replaced.
+func redactHeaders(header http.Header) http.Header {
+	clone := header.Clone()
+
+	if clone.Get("AccessKey") != "" {
+		clone.Set("AccessKey", redacted)
+	}
+
+	return clone
+}
+
+// peekBody reads up to maxErrorBodySize bytes of the body of resp and returns
+// them, leaving the body readable from the start.
+func peekBody(resp *http.Response) string {
+	head, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(head), &errReader{err: err}, resp.Body),
+		Closer: resp.Body,
+	}
+
+	return string(head)
+}
+
+// errReader is an io.Reader returning err, or io.EOF if err is nil.
+type errReader struct {
+	err error
+}
+
+// Read implements io.Reader.
+func (r *errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	return 0, io.EOF
+}
@@ -0,0 +1,301 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+// logBuffer is a concurrency-safe buffer of JSON log records.
+type logBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// records returns the decoded records with the given message.
+func (b *logBuffer) records(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+func newLogger(w *logBuffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+func TestConfig_Logger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		level       slog.Level
+		errorBodies bool
+		middleware  []bunnystorage.Middleware
+		call        func(ctx context.Context, client *bunnystorage.Client) error
+		want        map[string]any
+		wantLevel   string
+		wantBody    bool
+		wantNone    bool
+	}{
+		{
+			name:  "success",
+			level: slog.LevelDebug,
+			call: func(ctx context.Context, client *bunnystorage.Client) error {
+				_, err := client.Upload(ctx, "/docs", "file.txt", "", strings.NewReader("content"))
+
+				return err
+			},
+			want: map[string]any{
+				"operation":      "upload",
+				"method":         http.MethodPut,
+				"path":           "/" + bunnystoragetest.DefaultStorageZone + "/docs/file.txt",
+				"status":         float64(http.StatusCreated),
+				"request_bytes":  float64(len("content")),
+				"attempt":        float64(1),
+				"response_bytes": float64(len(`{"HttpCode":201,"Message":"File uploaded."}`)),
+			},
+			wantLevel: "DEBUG",
+		},
+		{
+			name:  "unknown response length",
+			level: slog.LevelDebug,
+			middleware: []bunnystorage.Middleware{
+				func(next http.RoundTripper) http.RoundTripper {
+					return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						resp, err := next.RoundTrip(req)
+						if err == nil {
+							resp.ContentLength = -1
+						}
+
+						return resp, err
+					})
+				},
+			},
+			call: func(ctx context.Context, client *bunnystorage.Client) error {
+				_, _, err := client.List(ctx, "/")
+
+				return err
+			},
+			want: map[string]any{
+				"operation":      "list",
+				"response_bytes": float64(0),
+			},
+			wantLevel: "DEBUG",
+		},
+		{
+			name:  "success below level",
+			level: slog.LevelInfo,
+			call: func(ctx context.Context, client *bunnystorage.Client) error {
+				_, _, err := client.List(ctx, "/")
+
+				return err
+			},
+			wantNone: true,
+		},
+		{
+			name:  "error",
+			level: slog.LevelInfo,
+			call: func(ctx context.Context, client *bunnystorage.Client) error {
+				_, _, err := client.Download(ctx, "/", "missing.txt")
+
+				return err
+			},
+			want: map[string]any{
+				"operation": "download",
+				"method":    http.MethodGet,
+				"status":    float64(http.StatusNotFound),
+			},
+			wantLevel: "WARN",
+		},
+		{
+			name:        "error with body",
+			level:       slog.LevelDebug,
+			errorBodies: true,
+			call: func(ctx context.Context, client *bunnystorage.Client) error {
+				_, _, err := client.Download(ctx, "/", "missing.txt")
+
+				return err
+			},
+			want: map[string]any{
+				"operation": "download",
+				"status":    float64(http.StatusNotFound),
+			},
+			wantLevel: "WARN",
+			wantBody:  true,
+		},
+		{
+			name:  "error without body",
+			level: slog.LevelDebug,
+			call: func(ctx context.Context, client *bunnystorage.Client) error {
+				_, _, err := client.Download(ctx, "/", "missing.txt")
+
+				return err
+			},
+			want: map[string]any{
+				"operation": "download",
+			},
+			wantLevel: "WARN",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var logs logBuffer
+
+			srv := bunnystoragetest.NewServer()
+			t.Cleanup(srv.Close)
+
+			cfg := srv.Config()
+			cfg.Logger = newLogger(&logs, tt.level)
+			cfg.LogErrorBodies = tt.errorBodies
+			cfg.Middleware = tt.middleware
+
+			client, err := bunnystorage.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			callErr := tt.call(context.Background(), client)
+
+			if strings.Contains(logs.String(), srv.Key) || strings.Contains(logs.String(), srv.ReadOnlyKey) {
+				t.Errorf("logs contain an access key: %s", logs.String())
+			}
+
+			records := logs.records(t, "request completed")
+
+			if tt.wantNone {
+				if callErr != nil || len(records) != 0 {
+					t.Fatalf("got %d records and error %v, want none", len(records), callErr)
+				}
+
+				return
+			}
+
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1: %s", len(records), logs.String())
+			}
+
+			record := records[0]
+
+			if record["level"] != tt.wantLevel {
+				t.Errorf("level = %v, want %v", record["level"], tt.wantLevel)
+			}
+
+			for key, want := range tt.want {
+				if got := record[key]; got != want {
+					t.Errorf("%s = %v (%T), want %v (%T)", key, got, got, want, want)
+				}
+			}
+
+			if _, ok := record["duration"]; !ok {
+				t.Error("record has no duration")
+			}
+
+			body, hasBody := record["body"].(string)
+			if hasBody != tt.wantBody {
+				t.Errorf("record has body %v, want %v", hasBody, tt.wantBody)
+			}
+
+			if tt.wantBody && !strings.Contains(body, "Object Not Found") {
+				t.Errorf("body = %q, want the error message", body)
+			}
+
+			// Logging the body must not consume it.
+			var apiErr *bunnystorage.APIError
+			if errors.As(callErr, &apiErr) && apiErr.Message != "Object Not Found" {
+				t.Errorf("APIError.Message = %q, want %q", apiErr.Message, "Object Not Found")
+			}
+		})
+	}
+}
+
+func TestConfig_Logger_Attempts(t *testing.T) {
+	t.Parallel()
+
+	var logs logBuffer
+
+	rt := &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport:  rt,
+		MaxRetries: 1,
+		Logger:     newLogger(&logs, slog.LevelDebug),
+		LogLevel:   slog.LevelInfo,
+	})
+
+	if _, _, err := client.List(context.Background(), "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	records := logs.records(t, "request completed")
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %s", len(records), logs.String())
+	}
+
+	want := []struct {
+		level   string
+		status  float64
+		attempt float64
+	}{
+		{level: "WARN", status: http.StatusServiceUnavailable, attempt: 1},
+		{level: "INFO", status: http.StatusOK, attempt: 2},
+	}
+
+	for i, w := range want {
+		r := records[i]
+
+		if r["level"] != w.level || r["status"] != w.status || r["attempt"] != w.attempt {
+			t.Errorf("record %d = level %v, status %v, attempt %v; want %v, %v, %v",
+				i, r["level"], r["status"], r["attempt"], w.level, w.status, w.attempt)
+		}
+
+		headers, _ := r["headers"].(map[string]any)
+		if got, _ := headers["Accesskey"].([]any); len(got) != 1 || got[0] != "REDACTED" {
+			t.Errorf("record %d AccessKey header = %v, want redacted", i, headers["Accesskey"])
+		}
+	}
+}
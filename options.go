@@ -0,0 +1,49 @@
+package bunnystorage
+
+// UploadOption configures a call to Client.Upload.
+type UploadOption func(*uploadOptions)
+
+// uploadOptions holds the options of a call to Client.Upload.
+type uploadOptions struct {
+	progress     ProgressFunc
+	autoChecksum bool
+}
+
+// newUploadOptions returns the upload options resulting from applying opts.
+func newUploadOptions(opts []UploadOption) *uploadOptions {
+	o := &uploadOptions{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// DownloadOption configures a call to Client.Download or
+// Client.DownloadStream.
+type DownloadOption func(*downloadOptions)
+
+// downloadOptions holds the options of a call to Client.Download or
+// Client.DownloadStream.
+type downloadOptions struct {
+	progress    ProgressFunc
+	checksum    string
+	verify      bool
+	ranged      bool
+	offset      int64
+	length      int64
+	noOverwrite bool
+}
+
+// newDownloadOptions returns the download options resulting from applying
+// opts.
+func newDownloadOptions(opts []DownloadOption) *downloadOptions {
+	o := &downloadOptions{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
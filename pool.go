@@ -0,0 +1,42 @@
+package bunnystorage
+
+import (
+	"context"
+	"sync"
+)
+
+// parallel calls fn for every index in [0, n) using at most limit concurrent
+// goroutines. Indexes that have not started when ctx is done are skipped.
+func parallel(ctx context.Context, limit, n int, fn func(ctx context.Context, i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, limit)
+	)
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+
+			return
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fn(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,187 @@
+package bunnystorage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Progress describes the state of an upload or download.
+type Progress struct {
+	// Transferred is the number of bytes sent or received so far by the
+	// current attempt.
+	Transferred int64
+
+	// Total is the size of the transfer in bytes, or -1 if it is unknown.
+	Total int64
+
+	// Elapsed is the time since the current attempt started.
+	Elapsed time.Duration
+
+	// Attempt is the number of the current attempt, starting at 1. It only
+	// grows beyond 1 for uploads whose body is sent again after a retry.
+	Attempt int
+}
+
+// Rate returns the average throughput of the current attempt in bytes per
+// second.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Transferred) / p.Elapsed.Seconds()
+}
+
+// ProgressFunc is called as the body of a transfer is read. It is called from
+// the goroutine reading the body and should return quickly.
+type ProgressFunc func(Progress)
+
+// WithUploadProgress makes Upload call fn every time a chunk of the body is
+// sent. When a request is retried, the body is read again from the start and
+// progress is reported anew with Transferred reset to zero and Attempt
+// increased.
+func WithUploadProgress(fn ProgressFunc) UploadOption {
+	return func(o *uploadOptions) {
+		o.progress = fn
+	}
+}
+
+// WithDownloadProgress makes Download and DownloadStream call fn every time a
+// chunk of the response body is received.
+func WithDownloadProgress(fn ProgressFunc) DownloadOption {
+	return func(o *downloadOptions) {
+		o.progress = fn
+	}
+}
+
+// progressReader is an io.ReadCloser that reports the number of bytes read
+// through it.
+type progressReader struct {
+	rc      io.ReadCloser
+	fn      ProgressFunc
+	start   time.Time
+	total   int64
+	n       int64
+	attempt int
+}
+
+// newProgressReader returns a progressReader reading from rc and reporting to
+// fn.
+func newProgressReader(rc io.ReadCloser, total int64, attempt int, fn ProgressFunc) *progressReader {
+	return &progressReader{
+		rc:      rc,
+		fn:      fn,
+		start:   time.Now(),
+		total:   total,
+		attempt: attempt,
+	}
+}
+
+// Read implements io.Reader.
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	if n > 0 {
+		r.n += int64(n)
+
+		r.fn(Progress{
+			Transferred: r.n,
+			Total:       r.total,
+			Elapsed:     time.Since(r.start),
+			Attempt:     r.attempt,
+		})
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is.
+}
+
+// Close implements io.Closer.
+func (r *progressReader) Close() error {
+	return r.rc.Close() //nolint:wrapcheck // errors from the wrapped body are returned as is.
+}
+
+// trackUploadProgress makes the body of req report its progress to fn,
+// including the fresh copies of the body sent when the request is retried.
+func trackUploadProgress(req *http.Request, fn ProgressFunc) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+
+	req.Body = newProgressReader(req.Body, total, 1, fn)
+
+	getBody := req.GetBody
+	if getBody == nil {
+		return
+	}
+
+	var attempt atomic.Int64
+
+	attempt.Store(1)
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		body, err := getBody()
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return newProgressReader(body, total, int(attempt.Add(1)), fn), nil
+	}
+}
+
+// trackDownloadProgress returns body wrapped to report its progress to fn, or
+// body itself if fn is nil.
+func trackDownloadProgress(body io.ReadCloser, total int64, fn ProgressFunc) io.ReadCloser {
+	if fn == nil {
+		return body
+	}
+
+	if total < 0 {
+		total = -1
+	}
+
+	return newProgressReader(body, total, 1, fn)
+}
+
+// seekerSize returns the number of bytes left to read from body if it is an
+// io.Seeker, leaving its offset unchanged, or -1 otherwise.
+func seekerSize(body io.Reader) int64 {
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return -1
+	}
+
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+
+	if _, err = seeker.Seek(current, io.SeekStart); err != nil {
+		return -1
+	}
+
+	return end - current
+}
+
+// contentLength returns the value of the Content-Length header, or -1 if it is
+// missing or invalid.
+func contentLength(header http.Header) int64 {
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return -1
+	}
+
+	return length
+}
@@ -0,0 +1,188 @@
+package bunnystorage_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+// progressRecorder records every progress report it receives.
+type progressRecorder struct {
+	reports []bunnystorage.Progress
+	mu      sync.Mutex
+}
+
+func (r *progressRecorder) record(p bunnystorage.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports = append(r.reports, p)
+}
+
+// last returns the last report of each attempt, keyed by attempt number.
+func (r *progressRecorder) last() map[int]bunnystorage.Progress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := make(map[int]bunnystorage.Progress)
+
+	for _, p := range r.reports {
+		last[p.Attempt] = p
+	}
+
+	return last
+}
+
+func TestClient_Upload_Progress(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+
+	content := bytes.Repeat([]byte("a"), 256<<10)
+
+	name := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(name, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var rec progressRecorder
+
+	if _, err = client.Upload(context.Background(), "/", "file.bin", "", file, bunnystorage.WithUploadProgress(rec.record)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if got, _ := srv.File("file.bin"); !bytes.Equal(got, content) {
+		t.Fatalf("Upload() stored %d bytes, want %d", len(got), len(content))
+	}
+
+	last := rec.last()
+	if len(last) != 1 {
+		t.Fatalf("progress reported %d attempts, want 1", len(last))
+	}
+
+	want := int64(len(content))
+	if p := last[1]; p.Transferred != want || p.Total != want {
+		t.Errorf("last progress = %+v, want Transferred and Total %d", p, want)
+	}
+}
+
+func TestClient_Upload_ProgressRetry(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusCreated}}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport:  rt,
+		MaxRetries: 1,
+	})
+
+	content := bytes.Repeat([]byte("a"), 256<<10)
+	want := int64(len(content))
+
+	var rec progressRecorder
+
+	_, err := client.Upload(context.Background(), "/", "file.bin", "", bytes.NewReader(content), bunnystorage.WithUploadProgress(rec.record))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	last := rec.last()
+	if len(last) != 2 {
+		t.Fatalf("progress reported %d attempts, want 2", len(last))
+	}
+
+	for attempt, p := range last {
+		if p.Transferred != want || p.Total != want {
+			t.Errorf("attempt %d: last progress = %+v, want Transferred and Total %d", attempt, p, want)
+		}
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	for i := 1; i < len(rec.reports); i++ {
+		prev, cur := rec.reports[i-1], rec.reports[i]
+
+		if cur.Attempt == prev.Attempt && cur.Transferred <= prev.Transferred {
+			t.Errorf("report %d: Transferred = %d, want more than %d", i, cur.Transferred, prev.Transferred)
+		}
+
+		if cur.Attempt != prev.Attempt && cur.Transferred >= want {
+			t.Errorf("report %d: Transferred = %d after retry, want it reset", i, cur.Transferred)
+		}
+	}
+}
+
+func TestClient_Download_Progress(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+
+	content := bytes.Repeat([]byte("a"), 256<<10)
+	want := int64(len(content))
+
+	srv.PutFile("file.bin", content)
+
+	tests := []struct {
+		name     string
+		download func(ctx context.Context, opt bunnystorage.DownloadOption) ([]byte, error)
+	}{
+		{
+			name: "download",
+			download: func(ctx context.Context, opt bunnystorage.DownloadOption) ([]byte, error) {
+				body, _, err := client.Download(ctx, "/", "file.bin", opt)
+
+				return body, err
+			},
+		},
+		{
+			name: "stream",
+			download: func(ctx context.Context, opt bunnystorage.DownloadOption) ([]byte, error) {
+				body, _, err := client.DownloadStream(ctx, "/", "file.bin", opt)
+				if err != nil {
+					return nil, err
+				}
+				defer body.Close()
+
+				return io.ReadAll(body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rec progressRecorder
+
+			got, err := tt.download(context.Background(), bunnystorage.WithDownloadProgress(rec.record))
+			if err != nil {
+				t.Fatalf("download error = %v", err)
+			}
+
+			if !bytes.Equal(got, content) {
+				t.Fatalf("download got %d bytes, want %d", len(got), len(content))
+			}
+
+			if p := rec.last()[1]; p.Transferred != want || p.Total != want {
+				t.Errorf("last progress = %+v, want Transferred and Total %d", p, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package bunnystorage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrInvalidRange is returned when a download is given an invalid byte range,
+// or a byte range combined with checksum verification.
+const ErrInvalidRange xerrors.Error = "invalid range"
+
+// WithRange makes Download and DownloadStream fetch only length bytes of the
+// file starting at offset. A negative length fetches everything from offset to
+// the end of the file.
+//
+// The offset must not be negative and the length must not be zero. Since only
+// part of the file is received, WithRange cannot be combined with
+// WithVerifyChecksum or WithExpectedChecksum.
+func WithRange(offset, length int64) DownloadOption {
+	return func(o *downloadOptions) {
+		o.ranged = true
+		o.offset = offset
+		o.length = length
+	}
+}
+
+// validateRange returns ErrInvalidRange if the range of o cannot be
+// requested.
+func (o *downloadOptions) validateRange() error {
+	if !o.ranged {
+		return nil
+	}
+
+	if o.offset < 0 || o.length == 0 {
+		return fmt.Errorf("%w: offset %d, length %d", ErrInvalidRange, o.offset, o.length)
+	}
+
+	if o.verify {
+		return fmt.Errorf("%w: cannot verify the checksum of a partial download", ErrInvalidRange)
+	}
+
+	return nil
+}
+
+// rangeHeader returns the value of the Range header requesting the range of
+// o.
+func (o *downloadOptions) rangeHeader() string {
+	value := "bytes=" + strconv.FormatInt(o.offset, 10) + "-"
+
+	if o.length > 0 {
+		value += strconv.FormatInt(o.offset+o.length-1, 10)
+	}
+
+	return value
+}
+
+// trimRange returns the part of content within the range of o if the server
+// ignored the Range header and sent the whole file, or content itself
+// otherwise.
+func (o *downloadOptions) trimRange(status int, content []byte) []byte {
+	if !o.ranged || status != http.StatusOK {
+		return content
+	}
+
+	if o.offset >= int64(len(content)) {
+		return content[:0]
+	}
+
+	content = content[o.offset:]
+
+	if o.length > 0 && o.length < int64(len(content)) {
+		content = content[:o.length]
+	}
+
+	return content
+}
+
+// trimRangeStream is like trimRange, but skips the bytes before the range in
+// body and limits reading to its length.
+func (o *downloadOptions) trimRangeStream(status int, body io.ReadCloser) (io.ReadCloser, error) {
+	if !o.ranged || status != http.StatusOK {
+		return body, nil
+	}
+
+	if _, err := io.CopyN(io.Discard, body, o.offset); err != nil && !errors.Is(err, io.EOF) {
+		_ = body.Close()
+
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	if o.length < 0 {
+		return body, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(body, o.length),
+		Closer: body,
+	}, nil
+}
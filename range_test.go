@@ -0,0 +1,134 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+// ignoreRange returns a middleware removing the Range header from every
+// request, like a server without range support.
+func ignoreRange() bunnystorage.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Del("Range")
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestClient_Download_Range(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("hello, range world")
+
+	tests := []struct {
+		name    string
+		opt     []bunnystorage.DownloadOption
+		want    string
+		wantErr error
+	}{
+		{
+			name: "prefix",
+			opt:  []bunnystorage.DownloadOption{bunnystorage.WithRange(0, 5)},
+			want: "hello",
+		},
+		{
+			name: "middle",
+			opt:  []bunnystorage.DownloadOption{bunnystorage.WithRange(7, 5)},
+			want: "range",
+		},
+		{
+			name: "suffix",
+			opt:  []bunnystorage.DownloadOption{bunnystorage.WithRange(13, -1)},
+			want: "world",
+		},
+		{
+			name:    "negative offset",
+			opt:     []bunnystorage.DownloadOption{bunnystorage.WithRange(-1, 5)},
+			wantErr: bunnystorage.ErrInvalidRange,
+		},
+		{
+			name:    "zero length",
+			opt:     []bunnystorage.DownloadOption{bunnystorage.WithRange(0, 0)},
+			wantErr: bunnystorage.ErrInvalidRange,
+		},
+		{
+			name: "with checksum verification",
+			opt: []bunnystorage.DownloadOption{
+				bunnystorage.WithRange(0, 5),
+				bunnystorage.WithVerifyChecksum(),
+			},
+			wantErr: bunnystorage.ErrInvalidRange,
+		},
+	}
+
+	for _, ignore := range []bool{false, true} {
+		ignore := ignore
+
+		for _, tt := range tests {
+			tt := tt
+
+			name := tt.name
+			if ignore {
+				name += " ignored by server"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				srv := bunnystoragetest.NewServer()
+				t.Cleanup(srv.Close)
+
+				srv.PutFile("file.txt", content)
+
+				cfg := srv.Config()
+				if ignore {
+					cfg.Middleware = []bunnystorage.Middleware{ignoreRange()}
+				}
+
+				client, err := bunnystorage.NewClient(cfg)
+				if err != nil {
+					t.Fatalf("NewClient() error = %v", err)
+				}
+
+				ctx := context.Background()
+
+				got, _, err := client.Download(ctx, "/", "file.txt", tt.opt...)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Download() error = %v, want %v", err, tt.wantErr)
+				}
+
+				if string(got) != tt.want {
+					t.Errorf("Download() = %q, want %q", got, tt.want)
+				}
+
+				body, _, err := client.DownloadStream(ctx, "/", "file.txt", tt.opt...)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DownloadStream() error = %v, want %v", err, tt.wantErr)
+				}
+
+				if err != nil {
+					return
+				}
+				defer body.Close()
+
+				got, err = io.ReadAll(body)
+				if err != nil {
+					t.Fatalf("DownloadStream() read error = %v", err)
+				}
+
+				if string(got) != tt.want {
+					t.Errorf("DownloadStream() = %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+}
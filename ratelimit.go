@@ -0,0 +1,96 @@
+package bunnystorage
+
+import (
+	"fmt"
+	"net/http"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimitWait is returned when a request cannot be sent because waiting
+// for the client-side rate limiter failed, usually because its context was
+// canceled or its deadline would be exceeded.
+const ErrRateLimitWait xerrors.Error = "rate limit wait failed"
+
+// RateLimit configures client-side rate limiting of the requests made to the
+// API.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained number of requests allowed per
+	// second. A value of zero or less disables rate limiting.
+	RequestsPerSecond float64
+
+	// Burst is the maximum number of requests allowed at once. It defaults to
+	// one if RequestsPerSecond is set.
+	Burst int
+}
+
+// limiter returns a rate.Limiter enforcing the rate limit, or nil if rate
+// limiting is disabled.
+func (r RateLimit) limiter() *rate.Limiter {
+	if r.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := r.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(r.RequestsPerSecond), burst)
+}
+
+// operationFor returns the Operation performed by a request with the given
+// HTTP method.
+func operationFor(method string) Operation {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return OperationRead
+	default:
+		return OperationWrite
+	}
+}
+
+// rateLimitTransport is an http.RoundTripper that waits for the limiter of the
+// operation performed by each request before sending it.
+type rateLimitTransport struct {
+	next  http.RoundTripper
+	read  *rate.Limiter
+	write *rate.Limiter
+}
+
+// newRateLimitTransport wraps next with the read and write rate limits of the
+// given Config, or returns next unchanged if rate limiting is disabled.
+func newRateLimitTransport(next http.RoundTripper, cfg *Config) http.RoundTripper {
+	t := &rateLimitTransport{
+		next:  next,
+		read:  cfg.ReadRateLimit.limiter(),
+		write: cfg.WriteRateLimit.limiter(),
+	}
+
+	if t.read == nil && t.write == nil {
+		return next
+	}
+
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := t.read
+	if operationFor(req.Method) == OperationWrite {
+		limiter = t.write
+	}
+
+	if limiter != nil {
+		if err := limiter.Wait(req.Context()); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+
+			return nil, fmt.Errorf("%w: %w", ErrRateLimitWait, err)
+		}
+	}
+
+	return t.next.RoundTrip(req) //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+}
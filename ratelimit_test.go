@@ -0,0 +1,96 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+func TestConfig_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeTransport{statuses: []int{http.StatusOK}}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport: rt,
+		ReadRateLimit: bunnystorage.RateLimit{
+			RequestsPerSecond: 20,
+			Burst:             1,
+		},
+		WriteRateLimit: bunnystorage.RateLimit{
+			RequestsPerSecond: 0.001,
+			Burst:             1,
+		},
+	})
+
+	ctx := context.Background()
+
+	start := time.Now()
+
+	for i := 0; i < 4; i++ {
+		if _, _, err := client.List(ctx, "/"); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 reads at 20 req/s took %v, want at least 150ms", elapsed)
+	}
+
+	if _, err := client.Upload(ctx, "/", "first.txt", "", strings.NewReader("first")); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+
+	_, err := client.Upload(ctx, "/", "second.txt", "", strings.NewReader("second"))
+	if !errors.Is(err, bunnystorage.ErrRateLimitWait) {
+		t.Fatalf("Upload() error = %v, want %v", err, bunnystorage.ErrRateLimitWait)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Upload() did not respect the context deadline")
+	}
+
+	if _, _, err = client.List(context.Background(), "/"); err != nil {
+		t.Errorf("List() error = %v, reads should not share the write limit", err)
+	}
+
+	if rt.count() != 6 {
+		t.Errorf("transport received %d requests, want 6", rt.count())
+	}
+}
+
+func TestConfig_RateLimit_Canceled(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeTransport{statuses: []int{http.StatusOK}}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport: rt,
+		ReadRateLimit: bunnystorage.RateLimit{
+			RequestsPerSecond: 0.001,
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if _, _, err := client.List(ctx, "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	cancel()
+
+	_, _, err := client.List(ctx, "/")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("List() error = %v, want %v", err, context.Canceled)
+	}
+}
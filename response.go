@@ -1,13 +1,22 @@
 package bunnystorage
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// timeFormat is the layout used by the Edge Storage API for timestamps, which
+// are expressed in UTC without a time zone designator.
+const timeFormat string = "2006-01-02T15:04:05.000"
 
 // Response represents a response from the BunnyCDN Storage API.
 type Response struct {
 	// Header contains the response headers.
 	Header http.Header
 
-	// Body contains the response body as a byte slice.
+	// Body contains the response body as a byte slice. It is nil for
+	// successful streamed responses, whose body is returned separately.
 	Body []byte
 
 	// Status is the HTTP status code of the response.
@@ -26,9 +35,56 @@ type Object struct {
 	Checksum        string `json:"Checksum,omitempty"`
 	DateCreated     string `json:"DateCreated,omitempty"`
 	GUID            string `json:"Guid,omitempty"`
-	Length          int    `json:"Length,omitempty"`
+	Length          int64  `json:"Length,omitempty"`
 	ServerID        int    `json:"ServerId,omitempty"`
 	StorageZoneID   int    `json:"StorageZoneId,omitempty"`
 	ArrayNumber     int    `json:"ArrayNumber,omitempty"`
 	IsDirectory     bool   `json:"IsDirectory,omitempty"`
 }
+
+// LastChangedTime returns the time the object was last modified, or the zero
+// time if LastChanged is missing or invalid.
+func (o *Object) LastChangedTime() time.Time {
+	return parseTime(o.LastChanged)
+}
+
+// DateCreatedTime returns the time the object was created, or the zero time
+// if DateCreated is missing or invalid.
+func (o *Object) DateCreatedTime() time.Time {
+	return parseTime(o.DateCreated)
+}
+
+// FullPath returns the path of the object including the storage zone name, as
+// in "/zone-name/dir/file.txt". Directories have no trailing slash.
+func (o *Object) FullPath() string {
+	return o.Path + o.ObjectName
+}
+
+// RelativePath returns the slash-separated path of the object relative to the
+// root of the storage zone, as in "dir/file.txt", or "." for the root itself.
+func (o *Object) RelativePath() string {
+	_, rel, _ := strings.Cut(strings.TrimPrefix(o.FullPath(), "/"), "/")
+	if rel = strings.Trim(rel, "/"); rel == "" {
+		return "."
+	}
+
+	return rel
+}
+
+// parseTime parses a timestamp returned by the Edge Storage API as UTC,
+// returning the zero time if it is invalid. The fractional seconds are
+// optional and may have any precision. Timestamps with a time zone designator
+// are also accepted.
+func parseTime(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", s, time.UTC)
+	if err == nil {
+		return t
+	}
+
+	t, err = time.Parse(time.RFC3339Nano, s)
+	if err == nil {
+		return t.UTC()
+	}
+
+	return time.Time{}
+}
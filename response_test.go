@@ -0,0 +1,176 @@
+package bunnystorage_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+func TestObject_Times(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{
+			name:  "milliseconds",
+			value: "2023-04-20T15:32:08.004",
+			want:  time.Date(2023, 4, 20, 15, 32, 8, 4000000, time.UTC),
+		},
+		{
+			name:  "no fractional seconds",
+			value: "2023-04-20T15:32:08",
+			want:  time.Date(2023, 4, 20, 15, 32, 8, 0, time.UTC),
+		},
+		{
+			name:  "seven fractional digits",
+			value: "2023-04-20T15:32:08.0040001",
+			want:  time.Date(2023, 4, 20, 15, 32, 8, 4000100, time.UTC),
+		},
+		{
+			name:  "time zone designator",
+			value: "2023-04-20T17:32:08.004+02:00",
+			want:  time.Date(2023, 4, 20, 15, 32, 8, 4000000, time.UTC),
+		},
+		{
+			name:  "empty",
+			value: "",
+		},
+		{
+			name:  "invalid",
+			value: "yesterday",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := &bunnystorage.Object{
+				LastChanged: tt.value,
+				DateCreated: tt.value,
+			}
+
+			if got := obj.LastChangedTime(); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("LastChangedTime() = %v, want %v", got, tt.want)
+			}
+
+			if got := obj.DateCreatedTime(); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("DateCreatedTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObject_Paths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		obj          *bunnystorage.Object
+		wantFull     string
+		wantRelative string
+	}{
+		{
+			name:         "file at root",
+			obj:          &bunnystorage.Object{Path: "/zone/", ObjectName: "file.txt"},
+			wantFull:     "/zone/file.txt",
+			wantRelative: "file.txt",
+		},
+		{
+			name:         "nested file",
+			obj:          &bunnystorage.Object{Path: "/zone/a/b/", ObjectName: "file.txt"},
+			wantFull:     "/zone/a/b/file.txt",
+			wantRelative: "a/b/file.txt",
+		},
+		{
+			name:         "directory",
+			obj:          &bunnystorage.Object{Path: "/zone/a/", ObjectName: "b", IsDirectory: true},
+			wantFull:     "/zone/a/b",
+			wantRelative: "a/b",
+		},
+		{
+			name:         "root",
+			obj:          &bunnystorage.Object{Path: "/zone/", IsDirectory: true},
+			wantFull:     "/zone/",
+			wantRelative: ".",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.obj.FullPath(); got != tt.wantFull {
+				t.Errorf("FullPath() = %q, want %q", got, tt.wantFull)
+			}
+
+			if got := tt.obj.RelativePath(); got != tt.wantRelative {
+				t.Errorf("RelativePath() = %q, want %q", got, tt.wantRelative)
+			}
+		})
+	}
+}
+
+func TestObject_JSON(t *testing.T) {
+	t.Parallel()
+
+	const payload = `{
+		"Guid": "e5d9d7f8-0000-0000-0000-000000000000",
+		"StorageZoneName": "zone",
+		"Path": "/zone/videos/",
+		"ObjectName": "large.mp4",
+		"Length": 5368709120,
+		"LastChanged": "2023-04-20T15:32:08.004",
+		"ServerId": 12,
+		"ArrayNumber": 3,
+		"IsDirectory": false,
+		"UserId": "00000000-0000-0000-0000-000000000000",
+		"ContentType": "",
+		"DateCreated": "2023-04-20T15:32:07.1",
+		"StorageZoneId": 1234,
+		"Checksum": "ABCDEF",
+		"ReplicatedZones": "DE,NY"
+	}`
+
+	var obj bunnystorage.Object
+	if err := json.Unmarshal([]byte(payload), &obj); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if obj.Length != 5<<30 {
+		t.Errorf("Length = %d, want %d", obj.Length, int64(5<<30))
+	}
+
+	encoded, err := json.Marshal(&obj)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got, want map[string]any
+
+	if err = json.Unmarshal(encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = json.Unmarshal([]byte(payload), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	// Empty and false values are omitted when encoding.
+	delete(want, "ContentType")
+	delete(want, "IsDirectory")
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %v, want %v", got, want)
+	}
+}
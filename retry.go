@@ -0,0 +1,327 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xnet/xhttp"
+)
+
+// Backoff returns the delay before a retry, given the number of retries made so
+// far, starting at zero, and the minimum and maximum delays of the policy.
+type Backoff func(retry int, minDelay, maxDelay time.Duration) time.Duration
+
+// ExponentialBackoff doubles the delay with every retry, starting at minDelay
+// and capped at maxDelay.
+func ExponentialBackoff(retry int, minDelay, maxDelay time.Duration) time.Duration {
+	return backoff(minDelay, maxDelay, retry)
+}
+
+// FullJitterBackoff picks a uniformly random delay between zero and the delay
+// of ExponentialBackoff, which spreads out the retries of many clients failing
+// at once. It is the default backoff strategy.
+func FullJitterBackoff(retry int, minDelay, maxDelay time.Duration) time.Duration {
+	ceiling := backoff(minDelay, maxDelay, retry)
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1)) //nolint:gosec // jitter does not need a secure source.
+}
+
+// RetryPolicy configures how a Client retries failed requests. The number of
+// retries is set by Config.MaxRetries.
+//
+// Requests are retried after a network error or a retryable status code, but
+// only if their method is idempotent and their body can be sent again, that is
+// if it is empty, was created from a byte slice or string reader, or
+// implements io.Seeker.
+type RetryPolicy struct {
+	// Backoff computes the delay before each retry. It defaults to
+	// FullJitterBackoff.
+	//
+	// This field is optional.
+	Backoff Backoff
+
+	// RetryableStatus reports whether a response with the given status code
+	// should be retried. It defaults to retrying 429, 500, 502, 503 and 504
+	// responses.
+	//
+	// This field is optional.
+	RetryableStatus func(status int) bool
+
+	// OnRetry is called before waiting for every retry, from the goroutine
+	// making the request.
+	//
+	// This field is optional.
+	OnRetry func(event RetryEvent)
+
+	// MinDelay is the delay before the first retry, which doubles with every
+	// retry. With FullJitterBackoff, it is the upper bound of the first
+	// random delay rather than a minimum. It defaults to one second.
+	//
+	// This field is optional.
+	MinDelay time.Duration
+
+	// MaxDelay is the maximum delay before a retry. It defaults to thirty
+	// seconds. If a Retry-After header asks to wait longer, the request is
+	// not retried.
+	//
+	// This field is optional.
+	MaxDelay time.Duration
+
+	// IgnoreRetryAfter disables honoring the Retry-After header of 429 and
+	// 503 responses, which otherwise sets the delay before the next retry
+	// when it is longer than the one computed by Backoff.
+	//
+	// This field is optional.
+	IgnoreRetryAfter bool
+}
+
+// RetryEvent describes a retry about to happen.
+type RetryEvent struct {
+	// Request is the request being retried.
+	Request *http.Request
+
+	// Err is the error of the failed attempt, if it failed with a network
+	// error.
+	Err error
+
+	// Attempt is the number of the attempt about to be made. The first retry
+	// is attempt 2.
+	Attempt int
+
+	// Status is the status code of the failed attempt, or zero if it failed
+	// with a network error.
+	Status int
+
+	// Delay is the time waited before the next attempt.
+	Delay time.Duration
+}
+
+// withDefaults returns a copy of p with its unset fields set to their default
+// values. It accepts a nil policy.
+func (p *RetryPolicy) withDefaults() *RetryPolicy {
+	var policy RetryPolicy
+	if p != nil {
+		policy = *p
+	}
+
+	if policy.Backoff == nil {
+		policy.Backoff = FullJitterBackoff
+	}
+
+	if policy.RetryableStatus == nil {
+		policy.RetryableStatus = isRetryableStatus
+	}
+
+	if policy.MinDelay == 0 {
+		policy.MinDelay = xhttp.DefaultMinRetryDelay
+	}
+
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = xhttp.DefaultMaxRetryDelay
+	}
+
+	if policy.MaxDelay < policy.MinDelay {
+		policy.MaxDelay = policy.MinDelay
+	}
+
+	return &policy
+}
+
+// validate returns an error if the delays of p are negative, or if both are
+// set and the minimum exceeds the maximum. It accepts a nil policy.
+func (p *RetryPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.MinDelay < 0 || p.MaxDelay < 0 || (p.MaxDelay > 0 && p.MinDelay > p.MaxDelay) {
+		return fmt.Errorf("%w: retry policy delays %v to %v", ErrInvalidConfig, p.MinDelay, p.MaxDelay)
+	}
+
+	return nil
+}
+
+// delay returns the delay before the given retry, starting at zero, taking
+// the Retry-After header of resp into account. It reports false if the server
+// asked to wait longer than MaxDelay.
+func (p *RetryPolicy) delay(retry int, resp *http.Response) (time.Duration, bool) {
+	delay := p.Backoff(retry, p.MinDelay, p.MaxDelay)
+
+	if resp == nil || p.IgnoreRetryAfter {
+		return delay, true
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return delay, true
+	}
+
+	after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return delay, true
+	}
+
+	if after > p.MaxDelay {
+		return 0, false
+	}
+
+	return max(delay, after), true
+}
+
+// shouldRetry reports whether req, which produced the given response and
+// error, should be retried.
+func (p *RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	ctx := req.Context()
+
+	if ctx.Err() != nil || !isIdempotent(req.Method) || !isReplayable(req) {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded) &&
+			!errors.Is(err, ErrRateLimitWait) &&
+			!errors.Is(err, ErrCircuitOpen)
+	}
+
+	return p.RetryableStatus(resp.StatusCode)
+}
+
+// isRetryableStatus reports whether a response with the given status code is
+// retried by default.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isIdempotent reports whether a request with the given method can safely be
+// sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either as a
+// number of seconds or as an HTTP date, and returns the time to wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// rewindableBody makes an io.ReadSeeker usable as a request body that can be
+// sent again, by seeking back to its initial offset for every attempt. Readers
+// from earlier attempts are detached, since the HTTP transport may still be
+// reading them when a new attempt starts.
+type rewindableBody struct {
+	r       io.ReadSeeker
+	current *rewindableReader
+	offset  int64
+	mu      sync.Mutex
+}
+
+// makeRewindable lets req be retried when its body is an io.ReadSeeker that
+// the standard library cannot replay on its own, such as an *os.File.
+func makeRewindable(req *http.Request, body io.Reader) {
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok || req.GetBody != nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+
+	b := &rewindableBody{
+		r:      seeker,
+		offset: offset,
+	}
+
+	req.Body = b.reader()
+	req.GetBody = b.getBody
+}
+
+// reader returns the reader of the first attempt.
+func (b *rewindableBody) reader() io.ReadCloser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current = &rewindableReader{body: b}
+
+	return b.current
+}
+
+// getBody implements http.Request.GetBody.
+func (b *rewindableBody) getBody() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, err := b.r.Seek(b.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	b.current = &rewindableReader{body: b}
+
+	return b.current, nil
+}
+
+// rewindableReader is the body of a single attempt of a request using a
+// rewindableBody.
+type rewindableReader struct {
+	body *rewindableBody
+}
+
+// Read implements io.Reader.
+func (r *rewindableReader) Read(p []byte) (int, error) {
+	r.body.mu.Lock()
+	defer r.body.mu.Unlock()
+
+	if r.body.current != r {
+		return 0, io.ErrClosedPipe
+	}
+
+	return r.body.r.Read(p) //nolint:wrapcheck // io.EOF must be returned as is.
+}
+
+// Close implements io.Closer. The underlying reader is left open, since it is
+// owned by the caller.
+func (r *rewindableReader) Close() error {
+	return nil
+}
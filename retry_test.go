@@ -0,0 +1,405 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// scriptedTransport is a transport replying with the given responses in order,
+// recording the body of every request it receives.
+type scriptedTransport struct {
+	responses []scriptedResponse
+	bodies    []string
+	mu        sync.Mutex
+}
+
+type scriptedResponse struct {
+	header http.Header
+	status int
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body []byte
+
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+	}
+
+	r := s.responses[len(s.bodies)%len(s.responses)]
+	s.bodies = append(s.bodies, string(body))
+
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	for k, v := range r.header {
+		header[k] = v
+	}
+
+	return &http.Response{
+		StatusCode: r.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func (s *scriptedTransport) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.bodies)
+}
+
+func TestFullJitterBackoff(t *testing.T) {
+	t.Parallel()
+
+	const (
+		minDelay = 10 * time.Millisecond
+		maxDelay = 100 * time.Millisecond
+	)
+
+	var belowMin bool
+
+	for retry := 0; retry < 10; retry++ {
+		ceiling := bunnystorage.ExponentialBackoff(retry, minDelay, maxDelay)
+
+		for i := 0; i < 100; i++ {
+			got := bunnystorage.FullJitterBackoff(retry, minDelay, maxDelay)
+			if got < 0 || got > ceiling {
+				t.Fatalf("FullJitterBackoff(%d) = %v, want between 0 and %v", retry, got, ceiling)
+			}
+
+			belowMin = belowMin || got < minDelay
+		}
+	}
+
+	if !belowMin {
+		t.Errorf("FullJitterBackoff() never returned less than %v", minDelay)
+	}
+
+	if got := bunnystorage.ExponentialBackoff(10, minDelay, maxDelay); got != maxDelay {
+		t.Errorf("ExponentialBackoff(10) = %v, want %v", got, maxDelay)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	retryAfter := func(value string) http.Header {
+		return http.Header{"Retry-After": []string{value}}
+	}
+
+	tests := []struct {
+		name         string
+		policy       *bunnystorage.RetryPolicy
+		responses    []scriptedResponse
+		wantRequests int
+		wantMinTime  time.Duration
+		wantErr      error
+	}{
+		{
+			name:         "retryable status",
+			responses:    []scriptedResponse{{status: http.StatusServiceUnavailable}, {status: http.StatusOK}},
+			wantRequests: 2,
+		},
+		{
+			name:         "non-retryable status",
+			responses:    []scriptedResponse{{status: http.StatusBadRequest}},
+			wantRequests: 1,
+			wantErr:      bunnystorage.ErrBadRequest,
+		},
+		{
+			name: "custom retryable status",
+			policy: &bunnystorage.RetryPolicy{
+				RetryableStatus: func(status int) bool {
+					return status == http.StatusBadRequest
+				},
+			},
+			responses:    []scriptedResponse{{status: http.StatusBadRequest}, {status: http.StatusOK}},
+			wantRequests: 2,
+		},
+		{
+			name: "custom non-retryable status",
+			policy: &bunnystorage.RetryPolicy{
+				RetryableStatus: func(int) bool {
+					return false
+				},
+			},
+			responses:    []scriptedResponse{{status: http.StatusServiceUnavailable}},
+			wantRequests: 1,
+			wantErr:      bunnystorage.ErrServerError,
+		},
+		{
+			name: "retry after seconds",
+			policy: &bunnystorage.RetryPolicy{
+				MaxDelay: 2 * time.Second,
+			},
+			responses: []scriptedResponse{
+				{status: http.StatusTooManyRequests, header: retryAfter("1")},
+				{status: http.StatusOK},
+			},
+			wantRequests: 2,
+			wantMinTime:  time.Second,
+		},
+		{
+			name: "retry after too long",
+			responses: []scriptedResponse{
+				{status: http.StatusServiceUnavailable, header: retryAfter("3600")},
+			},
+			wantRequests: 1,
+			wantErr:      bunnystorage.ErrServerError,
+		},
+		{
+			name: "retry after ignored",
+			policy: &bunnystorage.RetryPolicy{
+				IgnoreRetryAfter: true,
+			},
+			responses: []scriptedResponse{
+				{status: http.StatusServiceUnavailable, header: retryAfter("3600")},
+				{status: http.StatusOK},
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "retry after invalid",
+			responses: []scriptedResponse{
+				{status: http.StatusServiceUnavailable, header: retryAfter("soon")},
+				{status: http.StatusOK},
+			},
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := tt.policy
+			if policy == nil {
+				policy = &bunnystorage.RetryPolicy{}
+			}
+
+			if policy.MinDelay == 0 {
+				policy.MinDelay = time.Millisecond
+			}
+
+			if policy.MaxDelay == 0 {
+				policy.MaxDelay = 10 * time.Millisecond
+			}
+
+			rt := &scriptedTransport{responses: tt.responses}
+			client := newTransportClient(t, &bunnystorage.Config{
+				Transport:   rt,
+				RetryPolicy: policy,
+			})
+
+			start := time.Now()
+
+			_, _, err := client.List(context.Background(), "/")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("List() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if elapsed := time.Since(start); elapsed < tt.wantMinTime {
+				t.Errorf("List() took %v, want at least %v", elapsed, tt.wantMinTime)
+			}
+
+			if rt.count() != tt.wantRequests {
+				t.Errorf("transport received %d requests, want %d", rt.count(), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_OnRetry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		events []bunnystorage.RetryEvent
+	)
+
+	rt := &fakeTransport{statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport: rt,
+		RetryPolicy: &bunnystorage.RetryPolicy{
+			Backoff:  bunnystorage.ExponentialBackoff,
+			MinDelay: time.Millisecond,
+			MaxDelay: 10 * time.Millisecond,
+			OnRetry: func(event bunnystorage.RetryEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				events = append(events, event)
+			},
+		},
+	})
+
+	if _, _, err := client.List(context.Background(), "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := []bunnystorage.RetryEvent{
+		{Attempt: 2, Status: http.StatusBadGateway, Delay: time.Millisecond},
+		{Attempt: 3, Status: http.StatusServiceUnavailable, Delay: 2 * time.Millisecond},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("OnRetry called %d times, want %d", len(events), len(want))
+	}
+
+	for i, event := range events {
+		if event.Request == nil || event.Request.Method != http.MethodGet {
+			t.Errorf("events[%d].Request = %v, want the GET request", i, event.Request)
+		}
+
+		if event.Attempt != want[i].Attempt || event.Status != want[i].Status || event.Delay != want[i].Delay {
+			t.Errorf("events[%d] = %+v, want %+v", i, event, want[i])
+		}
+	}
+}
+
+func TestRetryPolicy_Logging(t *testing.T) {
+	t.Parallel()
+
+	logs := &logBuffer{}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport: &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}},
+		Logger:    newLogger(logs, slog.LevelDebug),
+		RetryPolicy: &bunnystorage.RetryPolicy{
+			Backoff:  bunnystorage.ExponentialBackoff,
+			MinDelay: time.Millisecond,
+			MaxDelay: time.Millisecond,
+		},
+	})
+
+	if _, _, err := client.List(context.Background(), "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	records := logs.records(t, "retrying request")
+	if len(records) != 1 {
+		t.Fatalf("logged %d retries, want 1", len(records))
+	}
+
+	want := map[string]any{
+		"operation": "list",
+		"method":    http.MethodGet,
+		"attempt":   float64(2),
+		"status":    float64(http.StatusServiceUnavailable),
+		"delay":     float64(time.Millisecond),
+	}
+
+	for key, value := range want {
+		if records[0][key] != value {
+			t.Errorf("record[%q] = %v, want %v", key, records[0][key], value)
+		}
+	}
+}
+
+func TestRetryPolicy_SeekableBody(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "file.txt")
+
+	if err := os.WriteFile(name, []byte("header:seekable body"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err = file.Seek(int64(len("header:")), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := &scriptedTransport{
+		responses: []scriptedResponse{
+			{status: http.StatusServiceUnavailable},
+			{status: http.StatusInternalServerError},
+			{status: http.StatusCreated},
+		},
+	}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport: rt,
+		RetryPolicy: &bunnystorage.RetryPolicy{
+			MinDelay: time.Millisecond,
+			MaxDelay: time.Millisecond,
+		},
+	})
+
+	if _, err = client.Upload(context.Background(), "/", "file.txt", "", file); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if rt.count() != 3 {
+		t.Fatalf("transport received %d requests, want 3", rt.count())
+	}
+
+	for i, body := range rt.bodies {
+		if body != "seekable body" {
+			t.Errorf("request %d body = %q, want %q", i, body, "seekable body")
+		}
+	}
+}
+
+func TestConfig_RetryPolicy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy *bunnystorage.RetryPolicy
+	}{
+		{
+			name:   "negative min delay",
+			policy: &bunnystorage.RetryPolicy{MinDelay: -time.Second},
+		},
+		{
+			name:   "negative max delay",
+			policy: &bunnystorage.RetryPolicy{MaxDelay: -time.Second},
+		},
+		{
+			name:   "min delay above max delay",
+			policy: &bunnystorage.RetryPolicy{MinDelay: time.Minute, MaxDelay: time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := bunnystorage.NewClient(&bunnystorage.Config{
+				StorageZone: "zone",
+				Key:         "key",
+				BaseURL:     "http://storage.invalid",
+				RetryPolicy: tt.policy,
+			})
+			if !errors.Is(err, bunnystorage.ErrInvalidConfig) {
+				t.Errorf("NewClient() error = %v, want %v", err, bunnystorage.ErrInvalidConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SyncOption configures a call to Client.Sync.
+type SyncOption func(*syncOptions)
+
+// syncOptions holds the options of a call to Client.Sync.
+type syncOptions struct {
+	concurrency int
+	delete      bool
+	dryRun      bool
+}
+
+// WithDelete makes Sync delete remote files that do not exist in the local
+// directory.
+func WithDelete() SyncOption {
+	return func(o *syncOptions) {
+		o.delete = true
+	}
+}
+
+// WithDryRun makes Sync report the changes it would make without uploading or
+// deleting anything.
+func WithDryRun() SyncOption {
+	return func(o *syncOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithSyncConcurrency sets the maximum number of files Sync hashes, uploads
+// or deletes at once. It defaults to Config.Concurrency.
+func WithSyncConcurrency(n int) SyncOption {
+	return func(o *syncOptions) {
+		o.concurrency = n
+	}
+}
+
+// SyncResult summarizes the changes made by Client.Sync. Every field holds
+// slash-separated paths relative to the synchronized directories, sorted in
+// lexical order.
+type SyncResult struct {
+	// Created lists the files that did not exist in the storage zone and were
+	// uploaded.
+	Created []string
+
+	// Updated lists the files whose checksum differed from the one in the
+	// storage zone and were uploaded.
+	Updated []string
+
+	// Deleted lists the remote files that did not exist locally and were
+	// deleted. It is only populated when using WithDelete.
+	Deleted []string
+
+	// Unchanged lists the files whose checksum matched the one in the storage
+	// zone.
+	Unchanged []string
+
+	// DryRun reports whether the changes were only computed, not made.
+	DryRun bool
+}
+
+// Sync makes the tree rooted at remotePath in the storage zone match the local
+// directory localDir, uploading files that are new or whose SHA256 checksum
+// differs from the one reported by the API.
+//
+// Local files are hashed and uploaded using a bounded pool of workers. If some
+// operations fail, Sync carries on with the others and returns the errors
+// joined together, along with a result describing the changes that succeeded.
+func (c *Client) Sync(ctx context.Context, localDir, remotePath string, opts ...SyncOption) (*SyncResult, error) {
+	o := &syncOptions{
+		concurrency: c.cfg.Concurrency,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	local, err := localFiles(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := path.Clean(strings.Trim(remotePath, "/"))
+
+	remote, err := c.remoteFiles(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &syncer{
+		client: c,
+		root:   root,
+		result: &SyncResult{DryRun: o.dryRun},
+		dryRun: o.dryRun,
+	}
+
+	names := make([]string, 0, len(local))
+
+	for name := range local {
+		names = append(names, name)
+	}
+
+	parallel(ctx, o.concurrency, len(names), func(ctx context.Context, i int) {
+		name := names[i]
+		s.upload(ctx, name, local[name], remote[name])
+	})
+
+	if o.delete {
+		var extra []string
+
+		for name := range remote {
+			if _, ok := local[name]; !ok {
+				extra = append(extra, name)
+			}
+		}
+
+		parallel(ctx, o.concurrency, len(extra), func(ctx context.Context, i int) {
+			s.delete(ctx, extra[i])
+		})
+	}
+
+	if err := ctx.Err(); err != nil {
+		s.fail(err)
+	}
+
+	s.result.sort()
+
+	return s.result, errors.Join(s.errs...)
+}
+
+// localFiles returns the regular files in the tree rooted at dir, mapping their
+// slash-separated path relative to dir to their path on disk.
+func localFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		files[filepath.ToSlash(rel)] = name
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return files, nil
+}
+
+// remoteFiles returns the files in the tree rooted at root in the storage
+// zone, keyed by their path relative to root. A missing root is treated as an
+// empty directory.
+func (c *Client) remoteFiles(ctx context.Context, root string) (map[string]*Object, error) {
+	files := make(map[string]*Object)
+
+	err := c.Walk(ctx, root, func(name string, obj *Object, err error) error {
+		if err != nil {
+			if name == root && errors.Is(err, ErrNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		if obj.IsDirectory {
+			return nil
+		}
+
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+
+		files[name] = obj
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return files, nil
+}
+
+// syncer holds the state of a single call to Sync.
+type syncer struct {
+	client *Client
+	result *SyncResult
+	root   string
+	errs   []error
+	mu     sync.Mutex
+	dryRun bool
+}
+
+// upload uploads the local file if it is new or changed.
+func (s *syncer) upload(ctx context.Context, name, localPath string, remote *Object) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		s.fail(fmt.Errorf("%s: %w", name, err))
+
+		return
+	}
+	defer file.Close()
+
+	checksum, err := ComputeSHA256(file)
+	if err != nil {
+		s.fail(fmt.Errorf("%s: %w", name, err))
+
+		return
+	}
+
+	if remote != nil && strings.EqualFold(remote.Checksum, checksum) {
+		s.record(&s.result.Unchanged, name)
+
+		return
+	}
+
+	if !s.dryRun {
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			s.fail(fmt.Errorf("%s: %w", name, err))
+
+			return
+		}
+
+		if _, err = s.client.Upload(ctx, s.remoteDir(name), path.Base(name), checksum, file); err != nil {
+			s.fail(fmt.Errorf("%s: %w", name, err))
+
+			return
+		}
+	}
+
+	if remote == nil {
+		s.record(&s.result.Created, name)
+	} else {
+		s.record(&s.result.Updated, name)
+	}
+}
+
+// delete deletes the remote file.
+func (s *syncer) delete(ctx context.Context, name string) {
+	if !s.dryRun {
+		if _, err := s.client.Delete(ctx, s.remoteDir(name), path.Base(name)); err != nil {
+			s.fail(fmt.Errorf("%s: %w", name, err))
+
+			return
+		}
+	}
+
+	s.record(&s.result.Deleted, name)
+}
+
+// remoteDir returns the remote directory of the file with the given relative
+// path.
+func (s *syncer) remoteDir(name string) string {
+	return apiPath(path.Join(s.root, path.Dir(name)))
+}
+
+// record appends name to the given list of the result.
+func (s *syncer) record(list *[]string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	*list = append(*list, name)
+}
+
+// fail records an error.
+func (s *syncer) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errs = append(s.errs, err)
+}
+
+// sort sorts every list of the result.
+func (r *SyncResult) sort() {
+	sort.Strings(r.Created)
+	sort.Strings(r.Updated)
+	sort.Strings(r.Deleted)
+	sort.Strings(r.Unchanged)
+}
@@ -0,0 +1,144 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/bunnystoragetest"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClient_Sync(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"index.html":     "<h1>Hello</h1>",
+		"css/style.css":  "body {}",
+		"img/logo.svg":   "<svg></svg>",
+		"img/icons/a.sv": "<svg/>",
+	})
+
+	srv.PutFile("site/img/logo.svg", []byte("<svg></svg>"))
+	srv.PutFile("site/index.html", []byte("<h1>Old</h1>"))
+	srv.PutFile("site/old.html", []byte("stale"))
+	srv.PutFile("other/keep.txt", []byte("keep"))
+
+	tests := []struct {
+		name      string
+		opts      []bunnystorage.SyncOption
+		want      *bunnystorage.SyncResult
+		wantFiles []string
+	}{
+		{
+			name: "dry_run",
+			opts: []bunnystorage.SyncOption{bunnystorage.WithDryRun(), bunnystorage.WithDelete()},
+			want: &bunnystorage.SyncResult{
+				Created:   []string{"css/style.css", "img/icons/a.sv"},
+				Updated:   []string{"index.html"},
+				Deleted:   []string{"old.html"},
+				Unchanged: []string{"img/logo.svg"},
+				DryRun:    true,
+			},
+			wantFiles: []string{"other/keep.txt", "site/img/logo.svg", "site/index.html", "site/old.html"},
+		},
+		{
+			name: "upload",
+			opts: []bunnystorage.SyncOption{bunnystorage.WithSyncConcurrency(2)},
+			want: &bunnystorage.SyncResult{
+				Created:   []string{"css/style.css", "img/icons/a.sv"},
+				Updated:   []string{"index.html"},
+				Unchanged: []string{"img/logo.svg"},
+			},
+			wantFiles: []string{
+				"other/keep.txt",
+				"site/css/style.css",
+				"site/img/icons/a.sv",
+				"site/img/logo.svg",
+				"site/index.html",
+				"site/old.html",
+			},
+		},
+		{
+			name: "delete",
+			opts: []bunnystorage.SyncOption{bunnystorage.WithDelete()},
+			want: &bunnystorage.SyncResult{
+				Deleted:   []string{"old.html"},
+				Unchanged: []string{"css/style.css", "img/icons/a.sv", "img/logo.svg", "index.html"},
+			},
+			wantFiles: []string{
+				"other/keep.txt",
+				"site/css/style.css",
+				"site/img/icons/a.sv",
+				"site/img/logo.svg",
+				"site/index.html",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Sync(ctx, dir, "/site/", tt.opts...)
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sync() = %+v, want %+v", got, tt.want)
+			}
+
+			if files := srv.Files(); !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("Files() = %v, want %v", files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestClient_Sync_ZoneRoot(t *testing.T) {
+	t.Parallel()
+
+	client, srv := bunnystoragetest.NewClient(t)
+
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"a.txt":     "a",
+		"sub/b.txt": "b",
+	})
+
+	got, err := client.Sync(context.Background(), dir, "/")
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if want := []string{"a.txt", "sub/b.txt"}; !reflect.DeepEqual(got.Created, want) {
+		t.Errorf("Sync() Created = %v, want %v", got.Created, want)
+	}
+
+	if want := []string{"a.txt", "sub/b.txt"}; !reflect.DeepEqual(srv.Files(), want) {
+		t.Errorf("Files() = %v, want %v", srv.Files(), want)
+	}
+}
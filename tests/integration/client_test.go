@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
@@ -208,8 +209,8 @@ func TestClient_Delete(t *testing.T) {
 	}
 
 	_, resp, err = client.Download(ctx, _testPath, testFile)
-	if err != nil {
-		t.Fatalf("download error: %v", err)
+	if !errors.Is(err, bunnystorage.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
 	}
 
 	if resp.Status != 404 {
@@ -0,0 +1,240 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// maxDrainSize is the maximum number of bytes drained from the body of a
+// response that is discarded before retrying a request.
+const maxDrainSize int64 = 64 << 10
+
+// Middleware wraps an http.RoundTripper to observe or modify the requests made
+// by a Client and the responses it receives.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as
+// HTTP round trippers.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newHTTPClient returns the HTTP client used by a Client created with the
+// given Config.
+//
+// The transport is built from the inside out: the base transport, taken from
+// Config.Transport, Config.HTTPClient or http.DefaultTransport in that order,
+// is wrapped by Config.Middleware so that the first middleware is the
+// outermost one, then by the rate limiter, the request logger, the circuit
+// breakers, the read failover and hedged reads, and the result is wrapped by
+// the retry logic, so middleware, rate limits and logging apply to every
+// attempt of a request in every region. Hedged reads are counted in hedges.
+func newHTTPClient(cfg *Config, hedges *hedgeCounters) *http.Client {
+	var httpc http.Client
+
+	if cfg.HTTPClient != nil {
+		httpc = *cfg.HTTPClient
+	}
+
+	base := cfg.Transport
+	if base == nil {
+		base = httpc.Transport
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for i := len(cfg.Middleware) - 1; i >= 0; i-- {
+		base = cfg.Middleware[i](base)
+	}
+
+	base = newRateLimitTransport(base, cfg)
+	base = newLoggingTransport(base, cfg)
+	base = newBreakerTransport(base, cfg)
+
+	regional := base
+
+	base = newFailoverTransport(base, cfg)
+	base = newHedgeTransport(base, regional, cfg, hedges)
+
+	retry := &retryTransport{
+		next:       base,
+		policy:     cfg.RetryPolicy.withDefaults(),
+		logger:     cfg.Logger,
+		level:      cfg.LogLevel,
+		maxRetries: cfg.MaxRetries,
+	}
+
+	if retry.level == nil {
+		retry.level = slog.LevelDebug
+	}
+
+	httpc.Transport = retry
+
+	httpc.Timeout = cfg.Timeout
+
+	return &httpc
+}
+
+// retryTransport is an http.RoundTripper that retries requests failing with a
+// network error or a retryable status code, following a RetryPolicy.
+type retryTransport struct {
+	next       http.RoundTripper
+	policy     *RetryPolicy
+	logger     *slog.Logger
+	level      slog.Leveler
+	maxRetries int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		attemptReq = attemptReq.WithContext(withAttempt(ctx, attempt+1))
+
+		resp, err := t.next.RoundTrip(attemptReq)
+
+		if attempt >= t.maxRetries || !t.policy.shouldRetry(req, resp, err) {
+			return resp, err //nolint:wrapcheck // errors from the wrapped transport are returned as is.
+		}
+
+		delay, ok := t.policy.delay(attempt, resp)
+		if !ok {
+			return resp, nil
+		}
+
+		event := RetryEvent{
+			Request: req,
+			Err:     err,
+			Attempt: attempt + 2,
+			Delay:   delay,
+		}
+
+		if resp != nil {
+			event.Status = resp.StatusCode
+
+			drainBody(resp)
+		}
+
+		t.log(ctx, &event)
+
+		if t.policy.OnRetry != nil {
+			t.policy.OnRetry(event)
+		}
+
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// log records a retry about to happen.
+func (t *retryTransport) log(ctx context.Context, event *RetryEvent) {
+	if t.logger == nil || !t.logger.Enabled(ctx, t.level.Level()) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", operationName(event.Request)),
+		slog.String("method", event.Request.Method),
+		slog.String("path", event.Request.URL.Path),
+		slog.Int("attempt", event.Attempt),
+		slog.Duration("delay", event.Delay),
+	}
+
+	if event.Status != 0 {
+		attrs = append(attrs, slog.Int("status", event.Status))
+	}
+
+	if event.Err != nil {
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+
+	t.logger.LogAttrs(ctx, t.level.Level(), "retrying request", attrs...)
+}
+
+// backoff returns the exponential backoff delay before the given retry
+// attempt, starting at minDelay and capped at maxDelay.
+func backoff(minDelay, maxDelay time.Duration, attempt int) time.Duration {
+	delay := minDelay << attempt
+	if delay <= 0 || delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
+
+// sleep waits for the given duration or until ctx is done, whichever happens
+// first, and returns the error of ctx in the latter case.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isReplayable reports whether the body of the request can be sent again.
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns the request to send for the given attempt, with a
+// fresh copy of the body for every attempt after the first.
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = body
+
+	return clone, nil
+}
+
+// drainBody reads a bounded amount of the response body and closes it, so the
+// underlying connection can be reused.
+func drainBody(resp *http.Response) {
+	_ = closeBody(resp.Body)
+}
+
+// closeBody reads a bounded amount of what is left of body and closes it, so
+// the underlying connection can be reused, and returns the errors of both
+// steps joined together.
+func closeBody(body io.ReadCloser) error {
+	var drainErr, closeErr error
+
+	if _, err := io.Copy(io.Discard, io.LimitReader(body, maxDrainSize)); err != nil {
+		drainErr = fmt.Errorf("drain response body: %w", err)
+	}
+
+	if err := body.Close(); err != nil {
+		closeErr = fmt.Errorf("close response body: %w", err)
+	}
+
+	return errors.Join(drainErr, closeErr)
+}
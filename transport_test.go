@@ -0,0 +1,191 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+)
+
+// fakeTransport is a transport replying with the given status codes in order,
+// recording every request it receives.
+type fakeTransport struct {
+	statuses []int
+	requests []*http.Request
+	mu       sync.Mutex
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+
+	status := f.statuses[len(f.requests)%len(f.statuses)]
+	f.requests = append(f.requests, req)
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func (f *fakeTransport) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.requests)
+}
+
+func newTransportClient(t *testing.T, cfg *bunnystorage.Config) *bunnystorage.Client {
+	t.Helper()
+
+	cfg.StorageZone = "zone"
+	cfg.Key = "key"
+	cfg.BaseURL = "http://storage.invalid"
+
+	client, err := bunnystorage.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	return client
+}
+
+func TestConfig_Transport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config func(rt http.RoundTripper) *bunnystorage.Config
+	}{
+		{
+			name: "transport",
+			config: func(rt http.RoundTripper) *bunnystorage.Config {
+				return &bunnystorage.Config{
+					Transport: rt,
+				}
+			},
+		},
+		{
+			name: "http_client",
+			config: func(rt http.RoundTripper) *bunnystorage.Config {
+				return &bunnystorage.Config{
+					HTTPClient: &http.Client{Transport: rt},
+				}
+			},
+		},
+		{
+			name: "transport_over_http_client",
+			config: func(rt http.RoundTripper) *bunnystorage.Config {
+				return &bunnystorage.Config{
+					HTTPClient: &http.Client{
+						Transport: bunnystorage.RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+							t.Error("HTTPClient transport used instead of Transport")
+
+							return nil, http.ErrNotSupported
+						}),
+					},
+					Transport: rt,
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := &fakeTransport{statuses: []int{http.StatusOK}}
+			client := newTransportClient(t, tt.config(rt))
+
+			if _, _, err := client.List(context.Background(), "/"); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			if rt.count() != 1 {
+				t.Errorf("transport received %d requests, want 1", rt.count())
+			}
+		})
+	}
+}
+
+func TestConfig_Middleware(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+		rt    = &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+	)
+
+	record := func(name string) bunnystorage.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return bunnystorage.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+
+				req = req.Clone(req.Context())
+				req.Header.Add("X-Middleware", name)
+
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport:  rt,
+		Middleware: []bunnystorage.Middleware{record("outer"), record("inner")},
+		MaxRetries: 1,
+	})
+
+	if _, _, err := client.List(context.Background(), "/"); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := []string{"outer", "inner", "outer", "inner"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+
+	if rt.count() != 2 {
+		t.Fatalf("transport received %d requests, want 2", rt.count())
+	}
+
+	if got := rt.requests[1].Header.Values("X-Middleware"); !reflect.DeepEqual(got, []string{"outer", "inner"}) {
+		t.Errorf("X-Middleware = %v, want %v", got, []string{"outer", "inner"})
+	}
+}
+
+func TestConfig_Transport_NonReplayableBody(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeTransport{statuses: []int{http.StatusServiceUnavailable}}
+
+	client := newTransportClient(t, &bunnystorage.Config{
+		Transport: rt,
+	})
+
+	body := io.MultiReader(strings.NewReader("not replayable"))
+
+	if _, err := client.Upload(context.Background(), "/", "file.txt", "", body); err == nil {
+		t.Fatal("Upload() error = nil, want error")
+	}
+
+	if rt.count() != 1 {
+		t.Errorf("transport received %d requests, want 1", rt.count())
+	}
+}
@@ -0,0 +1,242 @@
+package bunnystorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WalkFunc is the type of the function called by Walk to visit each file or
+// directory.
+//
+// The path argument is the slash-separated path of the object relative to the
+// root of the storage zone, without a leading slash. The root of the storage
+// zone itself is reported as ".".
+//
+// The error result returned by the function controls how Walk continues, and
+// follows the semantics of [fs.WalkDirFunc]: returning [fs.SkipDir] skips the
+// current directory, or the remaining files in the parent directory when
+// returned for a file, and returning [fs.SkipAll] stops the walk without an
+// error. Any other non-nil error stops the walk and is returned by Walk.
+//
+// If listing a directory fails, the function is called a second time for that
+// directory with the error, as with [fs.WalkDir].
+type WalkFunc func(path string, obj *Object, err error) error
+
+// Walk walks the tree rooted at root in the storage zone, calling fn for each
+// file or directory in the tree, including root.
+//
+// Objects are visited in lexical order and fn is never called concurrently,
+// but the listings of upcoming directories are fetched ahead of time using up
+// to Config.Concurrency concurrent requests.
+func (c *Client) Walk(ctx context.Context, root string, fn WalkFunc) error {
+	root = path.Clean(strings.Trim(root, "/"))
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	w := &walker{
+		client: c,
+		fn:     fn,
+		sem:    make(chan struct{}, c.cfg.Concurrency),
+	}
+
+	defer func() {
+		cancel()
+		w.wg.Wait()
+	}()
+
+	obj := c.directoryObject(root)
+
+	err := fn(root, obj, nil)
+	if err == nil {
+		err = w.walkDir(ctx, root, w.prefetch(ctx, root))
+	}
+
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// directoryObject returns a synthetic Object describing the directory at the
+// given path, used to report the root of a walk.
+func (c *Client) directoryObject(dir string) *Object {
+	parent, name := path.Split(dir)
+	if dir == "." {
+		parent, name = "", ""
+	}
+
+	prefix := "/" + c.cfg.StorageZone + "/"
+	if parent != "" {
+		prefix += parent
+	}
+
+	return &Object{
+		Path:            prefix,
+		ObjectName:      name,
+		StorageZoneName: c.cfg.StorageZone,
+		IsDirectory:     true,
+	}
+}
+
+// apiPath converts a slash-separated path relative to the storage zone root
+// into the form expected by the methods of Client.
+func apiPath(dir string) string {
+	if dir == "." {
+		return "/"
+	}
+
+	return dir
+}
+
+// walker holds the state of a single call to Walk.
+type walker struct {
+	client *Client
+	fn     WalkFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+}
+
+// listing is the pending result of listing a directory.
+type listing struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	objects []*Object
+	err     error
+}
+
+// prefetch starts listing the given directory in the background.
+func (w *walker) prefetch(ctx context.Context, dir string) *listing {
+	ctx, cancel := context.WithCancel(ctx)
+
+	l := &listing{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+		defer close(l.done)
+
+		select {
+		case w.sem <- struct{}{}:
+		case <-ctx.Done():
+			l.err = ctx.Err()
+
+			return
+		}
+
+		defer func() { <-w.sem }()
+
+		l.objects, _, l.err = w.client.List(ctx, apiPath(dir))
+
+		sort.Slice(l.objects, func(i, j int) bool {
+			return l.objects[i].ObjectName < l.objects[j].ObjectName
+		})
+	}()
+
+	return l
+}
+
+// wait blocks until the listing is complete or ctx is done.
+func (l *listing) wait(ctx context.Context) ([]*Object, error) {
+	select {
+	case <-l.done:
+		return l.objects, l.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w", ctx.Err())
+	}
+}
+
+// walkDir visits the contents of dir, whose listing is pending in l.
+func (w *walker) walkDir(ctx context.Context, dir string, l *listing) error {
+	defer l.cancel()
+
+	objects, err := l.wait(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%w", ctxErr)
+		}
+
+		err = w.fn(dir, w.client.directoryObject(dir), err)
+		if err != nil && !errors.Is(err, fs.SkipDir) {
+			return err
+		}
+
+		return nil
+	}
+
+	var (
+		pending = make([]*listing, len(objects))
+		dirs    []int
+		started int
+		visited int
+	)
+
+	for i, obj := range objects {
+		if obj.IsDirectory {
+			dirs = append(dirs, i)
+		}
+	}
+
+	// Release the listings fetched ahead of time for directories that are not
+	// visited because the walk stopped early.
+	defer func() {
+		for _, p := range pending {
+			if p != nil {
+				p.cancel()
+			}
+		}
+	}()
+
+	for i, obj := range objects {
+		for started < len(dirs) && started < visited+cap(w.sem) {
+			idx := dirs[started]
+			pending[idx] = w.prefetch(ctx, path.Join(dir, objects[idx].ObjectName))
+			started++
+		}
+
+		if err = ctx.Err(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		name := path.Join(dir, obj.ObjectName)
+
+		err = w.fn(name, obj, nil)
+
+		if !obj.IsDirectory {
+			if errors.Is(err, fs.SkipDir) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		visited++
+
+		if err == nil {
+			err = w.walkDir(ctx, name, pending[i])
+		}
+
+		pending[i].cancel()
+		pending[i] = nil
+
+		if err != nil && !errors.Is(err, fs.SkipDir) {
+			return err
+		}
+	}
+
+	return nil
+}
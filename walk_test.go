@@ -0,0 +1,178 @@
+package bunnystorage_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/bunnystorage-go"
+	"git.sr.ht/~jamesponddotco/bunnystorage-go/internal/testutil"
+)
+
+// walkTree returns the directory paths served by the mock server mapped to
+// their contents.
+func walkTree() map[string][]*bunnystorage.Object {
+	return map[string][]*bunnystorage.Object{
+		"/mock/": {
+			{Path: "/mock/", ObjectName: "b.txt", Length: 1},
+			{Path: "/mock/", ObjectName: "a", IsDirectory: true},
+		},
+		"/mock/a/": {
+			{Path: "/mock/a/", ObjectName: "d.txt", Length: 1},
+			{Path: "/mock/a/", ObjectName: "c", IsDirectory: true},
+			{Path: "/mock/a/", ObjectName: "e", IsDirectory: true},
+		},
+		"/mock/a/c/": {
+			{Path: "/mock/a/c/", ObjectName: "f.txt", Length: 1},
+		},
+		"/mock/a/e/": {},
+	}
+}
+
+func walkHandler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+
+	tree := walkTree()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/mock/broken/") {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		objects, ok := tree[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(objects); err != nil {
+			t.Errorf("Encode() error = %v", err)
+		}
+	}
+}
+
+func TestClient_Walk(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+		ctx           = context.Background()
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	mux.HandleFunc("/mock/", walkHandler(t))
+
+	tests := []struct {
+		name    string
+		root    string
+		skip    map[string]error
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "full_tree",
+			root: "/",
+			want: []string{".", "a", "a/c", "a/c/f.txt", "a/d.txt", "a/e", "b.txt"},
+		},
+		{
+			name: "subdirectory",
+			root: "/a/",
+			want: []string{"a", "a/c", "a/c/f.txt", "a/d.txt", "a/e"},
+		},
+		{
+			name: "skip_dir",
+			root: "/",
+			skip: map[string]error{"a/c": fs.SkipDir},
+			want: []string{".", "a", "a/c", "a/d.txt", "a/e", "b.txt"},
+		},
+		{
+			name: "skip_dir_on_file",
+			root: "/",
+			skip: map[string]error{"a/d.txt": fs.SkipDir},
+			want: []string{".", "a", "a/c", "a/c/f.txt", "a/d.txt", "b.txt"},
+		},
+		{
+			name: "skip_all",
+			root: "/",
+			skip: map[string]error{"a/c/f.txt": fs.SkipAll},
+			want: []string{".", "a", "a/c", "a/c/f.txt"},
+		},
+		{
+			name:    "callback_error",
+			root:    "/",
+			skip:    map[string]error{"a/d.txt": errors.New("stop")},
+			want:    []string{".", "a", "a/c", "a/c/f.txt", "a/d.txt"},
+			wantErr: true,
+		},
+		{
+			name: "list_error",
+			root: "/broken",
+			want: []string{"broken", "broken"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+
+			err := client.Walk(ctx, tt.root, func(path string, obj *bunnystorage.Object, err error) error {
+				got = append(got, path)
+
+				if err != nil {
+					return nil
+				}
+
+				if !obj.IsDirectory && path == "." {
+					t.Errorf("Walk() root is not a directory")
+				}
+
+				return tt.skip[path]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Walk() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk() visited = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Walk_Canceled(t *testing.T) {
+	var (
+		client        = testutil.SetupMockClient(t)
+		mux, teardown = testutil.SetupMockServer(t)
+	)
+
+	defer t.Cleanup(func() {
+		teardown()
+	})
+
+	mux.HandleFunc("/mock/", walkHandler(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := client.Walk(ctx, "/", func(path string, _ *bunnystorage.Object, _ error) error {
+		if path == "a" {
+			cancel()
+		}
+
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Walk() error = %v, want %v", err, context.Canceled)
+	}
+}